@@ -1,26 +1,52 @@
 package add
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/dnote-io/cli/core"
 	"github.com/dnote-io/cli/infra"
 	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var content string
+var delimiter string
+var jsonl bool
 
 var example = `
- * Open an editor to write content
+ * Open an editor to write content. It is saved as a draft while you write
  dnote add git
 
  * Skip the editor by providing content directly
- dnote add git -c "time is a part of the commit hash"`
+ dnote add git -c "time is a part of the commit hash"
+
+ * Add to the book of the current workspace
+ dnote add -c "time is a part of the commit hash"
+
+ * Add to the book chosen by the rules in dnoterc
+ dnote add -c "git rebase --onto main feature"
+
+ * Add a note for each section of the input separated by a delimiter
+ cat notes.txt | dnote add git --split "---"
+
+ * Add a note for each line of JSON such as {"content": "..."}
+ cat notes.jsonl | dnote add git --jsonl`
+
+// jsonNote is a note in the input given with the jsonl flag
+type jsonNote struct {
+	Content string `json:"content"`
+}
 
 func preRun(cmd *cobra.Command, args []string) error {
-	if len(args) != 1 {
+	if len(args) > 1 {
 		return errors.New("Incorrect number of argument")
 	}
 
@@ -39,71 +65,372 @@ func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
 
 	f := cmd.Flags()
 	f.StringVarP(&content, "content", "c", "", "The new content for the note")
+	f.StringVarP(&delimiter, "split", "", "", "Read notes from stdin separated by the delimiter")
+	f.BoolVarP(&jsonl, "jsonl", "", false, "Read notes from stdin as one JSON object per line")
 
 	return cmd
 }
 
 func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
-		bookName := args[0]
+		bookName, err := core.GetBookName(args)
+		if err != nil {
+			return err
+		}
+		if err := checkBookName(ctx, bookName); err != nil {
+			return err
+		}
+
+		if delimiter != "" || jsonl {
+			contents, err := readStdinNotes()
+			if err != nil {
+				return errors.Wrap(err, "Failed to read notes from stdin")
+			}
+			if len(contents) == 0 {
+				return errors.New("Empty content")
+			}
+
+			groups, err := groupNotes(ctx, bookName, contents)
+			if err != nil {
+				return err
+			}
+
+			ts := time.Now().Unix()
+			if err := writeNotes(ctx, groups, ts); err != nil {
+				return errors.Wrap(err, "Failed to write notes")
+			}
 
+			for _, g := range groups {
+				log.Successf("added %d notes to %s\n", len(g.Contents), g.BookName)
+			}
+
+			lint(ctx, contents)
+			return nil
+		}
+
+		var draft *draftWriter
 		if content == "" {
+			draft, err = newDraftWriter(ctx, bookName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to prepare the draft")
+			}
+
 			fpath := core.GetDnoteTmpContentPath(ctx)
-			err := core.GetEditorInput(ctx, fpath, &content)
+			err := core.GetEditorInputWithAutosave(ctx, fpath, &content, draft.save)
 			if err != nil {
-				return errors.Wrap(err, "Failed to get editor input")
+				return errors.Wrap(err, "Failed to get editor input. Run the command again to continue the draft")
 			}
+			draft.warn()
 		}
 
 		if content == "" {
+			if draft != nil {
+				draft.discard()
+			}
+
 			return errors.New("Empty content")
 		}
 
+		groups, err := groupNotes(ctx, bookName, []string{content})
+		if err != nil {
+			return err
+		}
+
 		ts := time.Now().Unix()
-		note := core.NewNote(content, ts)
-		err := writeNote(ctx, bookName, note, ts)
+		err = writeNotes(ctx, groups, ts)
 		if err != nil {
 			return errors.Wrap(err, "Failed to write note")
 		}
 
+		if draft != nil {
+			draft.discard()
+		}
+
 		log.Printf("note: \"%s\"\n", content)
-		log.Successf("added to %s\n", bookName)
+		log.Successf("added to %s\n", groups[0].BookName)
+
+		lint(ctx, []string{content})
 		return nil
 	}
 }
 
-func writeNote(ctx infra.DnoteCtx, bookName string, note infra.Note, ts int64) error {
+// checkBookName returns an error if the book name is empty and there are no
+// rules to choose the book, so that it fails before the editor is opened
+func checkBookName(ctx infra.DnoteCtx, bookName string) error {
+	if bookName != "" {
+		return nil
+	}
+
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the config")
+	}
+	if len(config.Rules) == 0 {
+		return errors.New("Missing book name. Provide one, run `dnote workspace init <book name>`, or add rules to dnoterc")
+	}
+
+	return nil
+}
+
+// draftWriter saves the content written in the editor as the draft of the
+// book, so that it can be recovered on the next run if it is lost
+type draftWriter struct {
+	ctx      infra.DnoteCtx
+	config   infra.DraftConfig
+	bookName string
+	// id tells apart the draft if the book is chosen by the rules
+	id  string
+	err error
+}
+
+// newDraftWriter returns a writer of the draft of the book. If a draft of the
+// book was left by a previous run, the user is asked whether to continue it,
+// and the editor is opened with its content if so. Otherwise it is discarded.
+// If the book is chosen by the rules, the most recent draft whose book is
+// chosen by the rules is offered, and a new draft is given its own ID.
+func newDraftWriter(ctx infra.DnoteCtx, bookName string) (*draftWriter, error) {
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the config")
+	}
 	dnote, err := core.GetDnote(ctx)
 	if err != nil {
-		return errors.Wrap(err, "Failed to get dnote")
+		return nil, errors.Wrap(err, "Failed to read dnote")
+	}
+
+	ret := &draftWriter{ctx: ctx, config: config.Drafts, bookName: core.ResolveBookName(dnote, bookName)}
+	if ret.bookName == "" {
+		ret.id = utils.GenerateUID()
+	}
+
+	d, ok, err := core.FindDraft(ctx, ret.config, ret.bookName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the drafts")
+	}
+	if !ok || strings.TrimSpace(d.Content) == "" {
+		return ret, nil
 	}
 
-	var book infra.Book
+	savedOn := time.Unix(d.SavedOn, 0).Format("2006-01-02 15:04")
+	resume, err := utils.AskConfirmation(fmt.Sprintf("a draft saved on %s was not added: %s. continue it?", savedOn, core.GetTitle(d.Content)))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get confirmation")
+	}
 
-	book, ok := dnote[bookName]
-	if ok {
-		notes := append(dnote[bookName].Notes, note)
-		dnote[bookName] = core.GetUpdatedBook(dnote[bookName], notes)
-	} else {
-		book = core.NewBook(bookName)
-		book.Notes = []infra.Note{note}
-		dnote[bookName] = book
+	// The content of a previous run may be left in the file if the editor failed
+	var initial string
+	if resume {
+		initial = d.Content
+	}
+	if err := ioutil.WriteFile(core.GetDnoteTmpContentPath(ctx), []byte(initial), 0644); err != nil {
+		return nil, errors.Wrap(err, "Failed to write the draft to the temporary file")
+	}
+
+	if resume {
+		ret.id = d.ID
+	} else if err := core.RemoveDraft(ctx, ret.config, d); err != nil {
+		log.Warnf("failed to remove the draft: %s\n", err.Error())
+	}
 
-		err = core.LogActionAddBook(ctx, bookName)
+	return ret, nil
+}
+
+// save saves the content as the draft. It is called while the editor is open,
+// so the error is kept to be printed after the editor exits.
+func (w *draftWriter) save(content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	draft := core.Draft{BookName: w.bookName, ID: w.id, Content: content, SavedOn: time.Now().Unix()}
+	if err := core.SaveDraft(w.ctx, w.config, draft); err != nil {
+		w.err = err
+	}
+}
+
+// warn prints the last error saving the draft, if any
+func (w *draftWriter) warn() {
+	if w.err != nil {
+		log.Warnf("failed to save the draft: %s\n", w.err.Error())
+	}
+}
+
+// discard removes the draft once it is no longer needed. Failing to do so only
+// prints a warning because the note is already saved.
+func (w *draftWriter) discard() {
+	if err := core.RemoveDraft(w.ctx, w.config, core.Draft{BookName: w.bookName, ID: w.id}); err != nil {
+		log.Warnf("failed to remove the draft: %s\n", err.Error())
+	}
+}
+
+// lint prints the warnings of the linters enabled in the config. Failing to
+// run a linter only prints a warning because the note is already saved.
+func lint(ctx infra.DnoteCtx, contents []string) {
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		log.Warnf("failed to lint: %s\n", err.Error())
+		return
+	}
+
+	for _, c := range contents {
+		warnings, err := core.LintContent(config.Lint, c)
+		if err != nil {
+			log.Warnf("failed to lint: %s\n", err.Error())
+			return
+		}
+
+		for _, w := range warnings {
+			log.Warnf("%s: %s\n", w.Linter, w.Message)
+		}
+	}
+}
+
+// noteGroup is the contents of the notes to be added to a book
+type noteGroup struct {
+	BookName string
+	Contents []string
+}
+
+// groupNotes groups the contents by the book they are added to. If the book
+// name is empty, the book of each note is chosen by the rules in the config.
+func groupNotes(ctx infra.DnoteCtx, bookName string, contents []string) ([]noteGroup, error) {
+	if bookName != "" {
+		return []noteGroup{{BookName: bookName, Contents: contents}}, nil
+	}
+
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the config")
+	}
+
+	var ret []noteGroup
+	indices := map[string]int{}
+
+	for _, c := range contents {
+		rule, ok, err := core.MatchRule(config.Rules, c)
 		if err != nil {
-			return errors.Wrap(err, "Failed to log action")
+			return nil, errors.Wrap(err, "Failed to apply the rules")
+		}
+		if !ok {
+			return nil, errors.New("Missing book name. Provide one, run `dnote workspace init <book name>`, or add a rule matching the note")
+		}
+
+		idx, ok := indices[rule.Book]
+		if !ok {
+			idx = len(ret)
+			indices[rule.Book] = idx
+			ret = append(ret, noteGroup{BookName: rule.Book})
+		}
+
+		ret[idx].Contents = append(ret[idx].Contents, c)
+	}
+
+	return ret, nil
+}
+
+// readStdinNotes reads the contents of the notes from stdin, either separated
+// by the delimiter or encoded as one JSON object per line
+func readStdinNotes() ([]string, error) {
+	var ret []string
+
+	if jsonl {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var n jsonNote
+			if err := json.Unmarshal([]byte(line), &n); err != nil {
+				return ret, errors.Wrapf(err, "Failed to parse line %d", lineNum)
+			}
+			if c := strings.TrimSpace(n.Content); c != "" {
+				ret = append(ret, c)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return ret, errors.Wrap(err, "Failed to read stdin")
 		}
+
+		return ret, nil
 	}
 
-	err = core.LogActionAddNote(ctx, note.UUID, book.Name, note.Content, ts)
+	b, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
-		return errors.Wrap(err, "Failed to log action")
+		return ret, errors.Wrap(err, "Failed to read stdin")
+	}
+
+	for _, part := range strings.Split(string(b), delimiter) {
+		if c := strings.TrimSpace(part); c != "" {
+			ret = append(ret, c)
+		}
 	}
 
-	err = core.WriteDnote(ctx, dnote)
+	return ret, nil
+}
+
+// writeNotes adds the notes to their books, creating the books that do not
+// exist. The notes, the action log and the timestamp are each written once
+// however many notes are added.
+func writeNotes(ctx infra.DnoteCtx, groups []noteGroup, ts int64) error {
+	dnote, err := core.GetDnote(ctx)
 	if err != nil {
+		return errors.Wrap(err, "Failed to get dnote")
+	}
+
+	// Validate the names of the new books before logging any action
+	for i, g := range groups {
+		// Add to the existing book even if the name is typed in another case
+		groups[i].BookName = core.ResolveBookName(dnote, g.BookName)
+
+		if _, ok := dnote[groups[i].BookName]; !ok {
+			if err := core.ValidateBookName(groups[i].BookName); err != nil {
+				return err
+			}
+		}
+	}
+
+	var actions []core.Action
+	for _, g := range groups {
+		book, ok := dnote[g.BookName]
+		if !ok {
+			book = core.NewBook(g.BookName)
+
+			action, err := core.NewActionAddBook(g.BookName, ts)
+			if err != nil {
+				return errors.Wrap(err, "Failed to make the action")
+			}
+			actions = append(actions, action)
+		}
+
+		notes := book.Notes
+		for _, c := range g.Contents {
+			note := core.NewNote(c, ts)
+
+			action, err := core.NewActionAddNote(note.UUID, book.Name, note.Content, ts)
+			if err != nil {
+				return errors.Wrap(err, "Failed to make the action")
+			}
+			actions = append(actions, action)
+
+			notes = append(notes, note)
+		}
+
+		dnote[g.BookName] = core.GetUpdatedBook(book, notes)
+	}
+
+	if err := core.WriteDnote(ctx, dnote); err != nil {
 		return errors.Wrap(err, "Failed to write to dnote file")
 	}
+	if err := core.LogActions(ctx, actions); err != nil {
+		return errors.Wrap(err, "Failed to log actions")
+	}
 
 	return nil
 }
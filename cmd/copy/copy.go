@@ -0,0 +1,115 @@
+package copycmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var blockNum int
+var toStdout bool
+
+var example = `
+ * Copy the first code block of a note, or the note if it has none
+ dnote copy git 3
+
+ * Copy the second code block of a note
+ dnote copy git 3 --block 2
+
+ * Print the code block instead of copying it
+ dnote copy git 3 --stdout > script.sh`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("Incorrect number of argument")
+	}
+	if blockNum < 0 {
+		return errors.New("Block number must not be negative")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "copy <book name> <note index>",
+		Aliases: []string{"cp"},
+		Short:   "Copy a note or its code block to the clipboard",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.IntVarP(&blockNum, "block", "", 0, "The number of the code block to copy, starting from 1")
+	f.BoolVarP(&toStdout, "stdout", "", false, "Print the text instead of copying it")
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+		}
+		if idx < 0 || idx > len(book.Notes)-1 {
+			return errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+		}
+
+		text, err := getText(book.Notes[idx])
+		if err != nil {
+			return err
+		}
+
+		// The text is never run or interpreted, and is passed on byte for byte
+		if toStdout {
+			fmt.Print(text)
+			return nil
+		}
+
+		if err := utils.CopyToClipboard(text); err != nil {
+			return errors.Wrap(err, "Failed to copy to the clipboard")
+		}
+
+		log.Success("copied to the clipboard\n")
+		return nil
+	}
+}
+
+// getText returns the code block given by the block flag. Without the flag, it
+// returns the first code block or, if there is none, the content of the note.
+func getText(note infra.Note) (string, error) {
+	blocks := core.GetCodeBlocks(note.Content)
+
+	if blockNum == 0 {
+		if len(blocks) == 0 {
+			return note.Content, nil
+		}
+
+		return blocks[0].Code, nil
+	}
+
+	if blockNum > len(blocks) {
+		return "", errors.Errorf("The note has %d code blocks", len(blocks))
+	}
+
+	return blocks[blockNum-1].Code, nil
+}
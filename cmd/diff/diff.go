@@ -0,0 +1,270 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var since string
+var until string
+
+// sinceLastSync is the value of the since flag selecting the changes made
+// locally since the last sync and the changes downloaded by it
+var sinceLastSync = "last-sync"
+
+// dateLayout is the layout of the dates given to the since and until flags
+var dateLayout = "2006-01-02"
+
+var (
+	originLocal  = "local"
+	originRemote = "remote"
+)
+
+var example = `
+ * Show the local changes not yet synced and the changes downloaded by the last sync
+ dnote diff
+
+ * Show the changes made on or after a date
+ dnote diff --since 2018-01-01`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "diff",
+		Short:   "Show the notes changed locally and by the last sync",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&since, "since", "", sinceLastSync, "Show the changes since the last sync or on or after the date (YYYY-MM-DD)")
+	f.StringVarP(&until, "until", "", "", "Show the changes before the date (YYYY-MM-DD)")
+
+	return cmd
+}
+
+// change is a change to a book or a note made by an action
+type change struct {
+	Origin    string
+	Type      string
+	BookName  string
+	NoteUUID  string
+	Content   string
+	Timestamp int64
+	// Previous is the content of the note before the change, if known
+	Previous    string
+	HasPrevious bool
+}
+
+// window is the range of the timestamps of the changes to show
+type window struct {
+	Start int64
+	End   int64
+}
+
+func (w window) contains(ts int64) bool {
+	if w.Start != 0 && ts < w.Start {
+		return false
+	}
+	if w.End != 0 && ts >= w.End {
+		return false
+	}
+
+	return true
+}
+
+func parseDate(date string) (int64, error) {
+	if date == "" || date == sinceLastSync {
+		return 0, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, date, time.Local)
+	if err != nil {
+		return 0, errors.Errorf("Invalid date %s. Use the format YYYY-MM-DD", date)
+	}
+
+	return t.Unix(), nil
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		start, err := parseDate(since)
+		if err != nil {
+			return err
+		}
+		end, err := parseDate(until)
+		if err != nil {
+			return err
+		}
+		w := window{Start: start, End: end}
+
+		actions, err := core.ReadActionLog(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the action log")
+		}
+		record, err := core.ReadLastSync(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the last sync")
+		}
+
+		local, err := getChanges(originLocal, actions, map[string]string{}, w)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the local changes")
+		}
+		remote, err := getChanges(originRemote, record.Actions, record.Previous, w)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get the remote changes")
+		}
+
+		if log.Porcelain {
+			for _, c := range append(local, remote...) {
+				log.Fields(c.Origin, c.Type, c.BookName, c.NoteUUID, c.Timestamp)
+			}
+
+			return nil
+		}
+
+		log.Infof("local changes not yet synced (total %d)\n", len(local))
+		printChanges(local)
+
+		if record.SyncedAt == 0 {
+			log.Infof("no changes were downloaded by the last sync\n")
+			return nil
+		}
+
+		syncedAt := time.Unix(record.SyncedAt, 0).Format("2006-01-02 15:04")
+		log.Infof("changes downloaded by the last sync at %s (total %d)\n", syncedAt, len(remote))
+		printChanges(remote)
+
+		return nil
+	}
+}
+
+// getChanges returns the changes made by the actions in the window in the
+// order they were made. The known contents of the notes before the actions,
+// keyed by UUID, are used to show what edits changed.
+func getChanges(origin string, actions []core.Action, previous map[string]string, w window) ([]change, error) {
+	var ret []change
+
+	known := map[string]string{}
+	for uuid, content := range previous {
+		known[uuid] = content
+	}
+
+	for _, action := range actions {
+		c := change{Origin: origin, Type: action.Type, Timestamp: action.Timestamp}
+
+		switch action.Type {
+		case core.ActionAddNote:
+			var data core.AddNoteData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+
+			c.BookName, c.NoteUUID, c.Content = data.BookName, data.NoteUUID, data.Content
+			known[data.NoteUUID] = data.Content
+		case core.ActionEditNote:
+			var data core.EditNoteData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+
+			c.BookName, c.NoteUUID, c.Content = data.BookName, data.NoteUUID, data.Content
+			c.Previous, c.HasPrevious = known[data.NoteUUID]
+			known[data.NoteUUID] = data.Content
+		case core.ActionRemoveNote:
+			var data core.RemoveNoteData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+
+			c.BookName, c.NoteUUID = data.BookName, data.NoteUUID
+			c.Previous, c.HasPrevious = known[data.NoteUUID]
+			delete(known, data.NoteUUID)
+		case core.ActionAddBook:
+			var data core.AddBookData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+
+			c.BookName = data.BookName
+		case core.ActionRemoveBook:
+			var data core.RemoveBookData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+
+			c.BookName = data.BookName
+		default:
+			return ret, errors.Errorf("Unsupported action %s", action.Type)
+		}
+
+		if w.contains(action.Timestamp) {
+			ret = append(ret, c)
+		}
+	}
+
+	return ret, nil
+}
+
+func printChanges(changes []change) {
+	for _, c := range changes {
+		switch c.Type {
+		case core.ActionAddBook:
+			log.WithPrefixf(log.ColorGreen, "+", "added book %s", c.BookName)
+		case core.ActionRemoveBook:
+			log.WithPrefixf(log.ColorRed, "-", "removed book %s", c.BookName)
+		case core.ActionAddNote:
+			log.WithPrefixf(log.ColorGreen, "+", "added note to %s: %s", c.BookName, core.GetTitle(c.Content))
+		case core.ActionRemoveNote:
+			title := c.NoteUUID
+			if c.HasPrevious {
+				title = core.GetTitle(c.Previous)
+			}
+
+			log.WithPrefixf(log.ColorRed, "-", "removed note from %s: %s", c.BookName, title)
+		case core.ActionEditNote:
+			log.WithPrefixf(log.ColorYellow, "~", "edited note in %s: %s", c.BookName, core.GetTitle(c.Content))
+			printEdit(c)
+		}
+	}
+}
+
+// printEdit prints the lines changed by the edit, or the new content if the
+// content before the edit is not known
+func printEdit(c change) {
+	if !c.HasPrevious {
+		for _, line := range strings.Split(c.Content, "\n") {
+			log.Raw(fmt.Sprintf("      %s\n", line))
+		}
+
+		return
+	}
+
+	for _, l := range core.DiffLines(c.Previous, c.Content) {
+		switch l.Op {
+		case core.DiffDelete:
+			log.Raw(fmt.Sprintf("    \033[%dm- %s\033[0m\n", log.ColorRed, l.Text))
+		case core.DiffInsert:
+			log.Raw(fmt.Sprintf("    \033[%dm+ %s\033[0m\n", log.ColorGreen, l.Text))
+		default:
+			log.Raw(fmt.Sprintf("      %s\n", l.Text))
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package doctor
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var checkNetwork bool
+
+// dialTimeout is how long to wait for the TLS handshake
+var dialTimeout = 10 * time.Second
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+var example = `
+ * Check the local data
+ dnote doctor
+
+ * Also check the connection to the server, to report why sync does not work
+ dnote doctor --network`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "Diagnose problems with the local data and the connection to the server",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&checkNetwork, "network", "", false, "Check the DNS, TLS, authentication, clock, and latency of the connection to the server")
+
+	return cmd
+}
+
+// result is the outcome of a check. Err is nil if the check passed.
+type result struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		printHeader(ctx)
+
+		results := checkLocal(ctx)
+		if checkNetwork {
+			results = append(results, checkServer(ctx)...)
+		}
+
+		var failed int
+		for _, r := range results {
+			if r.Err != nil {
+				failed++
+			}
+
+			printResult(r)
+		}
+
+		if failed > 0 {
+			return errors.Errorf("%d of %d checks failed", failed, len(results))
+		}
+
+		log.Success("all checks passed\n")
+		return nil
+	}
+}
+
+// printHeader prints the environment so that the report can be shared as is
+func printHeader(ctx infra.DnoteCtx) {
+	if log.Porcelain {
+		log.Fields("version", core.Version)
+		log.Fields("platform", fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH))
+		log.Fields("endpoint", ctx.APIEndpoint)
+		return
+	}
+
+	log.Infof("dnote %s on %s/%s\n", core.Version, runtime.GOOS, runtime.GOARCH)
+	log.Infof("endpoint: %s\n", ctx.APIEndpoint)
+}
+
+func printResult(r result) {
+	if log.Porcelain {
+		status, detail := "ok", r.Detail
+		if r.Err != nil {
+			status, detail = "fail", r.Err.Error()
+		}
+
+		log.Fields(r.Name, status, detail)
+		return
+	}
+
+	if r.Err != nil {
+		log.Warnf("%s: %s\n", r.Name, r.Err.Error())
+		return
+	}
+
+	log.Printf("%s: %s\n", r.Name, r.Detail)
+}
+
+// checkLocal checks that the files in the dnote directory can be read
+func checkLocal(ctx infra.DnoteCtx) []result {
+	var ret []result
+
+	r := result{Name: "config"}
+	if _, err := core.ReadConfig(ctx); err != nil {
+		r.Err = err
+	} else {
+		r.Detail = core.GetConfigPath(ctx)
+	}
+	ret = append(ret, r)
+
+	r = result{Name: "notes"}
+	if dnote, err := core.GetDnote(ctx); err != nil {
+		r.Err = err
+	} else {
+		var count int
+		for _, book := range dnote {
+			count += len(book.Notes)
+		}
+		r.Detail = fmt.Sprintf("%d notes in %d books", count, len(dnote))
+	}
+	ret = append(ret, r)
+
+	r = result{Name: "actions"}
+	if actions, err := core.ReadActionLog(ctx); err != nil {
+		r.Err = err
+	} else {
+		r.Detail = fmt.Sprintf("%d changes to sync", len(actions))
+	}
+	ret = append(ret, r)
+
+	r = result{Name: "timestamps"}
+	if _, err := core.ReadTimestamp(ctx); err != nil {
+		r.Err = err
+	} else {
+		r.Detail = core.GetTimestampPath(ctx)
+	}
+	ret = append(ret, r)
+
+	return ret
+}
+
+// checkServer checks each step of connecting to the server, stopping at the
+// first one that fails because the later steps depend on it
+func checkServer(ctx infra.DnoteCtx) []result {
+	var ret []result
+
+	u, err := url.Parse(ctx.APIEndpoint)
+	if err != nil || u.Host == "" {
+		return append(ret, result{Name: "endpoint", Err: errors.Errorf("Invalid endpoint %s", ctx.APIEndpoint)})
+	}
+
+	r := checkDNS(u)
+	ret = append(ret, r)
+	if r.Err != nil {
+		return ret
+	}
+
+	r = checkTLS(u)
+	ret = append(ret, r)
+	if r.Err != nil {
+		return ret
+	}
+
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		return append(ret, result{Name: "request", Err: errors.Wrap(err, "Failed to read the config")})
+	}
+
+	return append(ret, checkRequest(ctx, config)...)
+}
+
+func checkDNS(u *url.URL) result {
+	r := result{Name: "dns"}
+
+	start := time.Now()
+	addrs, err := net.LookupHost(u.Hostname())
+	if err != nil {
+		r.Err = errors.Wrapf(err, "Failed to resolve %s", u.Hostname())
+		return r
+	}
+
+	r.Detail = fmt.Sprintf("%s resolved to %v in %s", u.Hostname(), addrs, time.Since(start).Round(time.Millisecond))
+	return r
+}
+
+func checkTLS(u *url.URL) result {
+	r := result{Name: "tls"}
+
+	if u.Scheme != "https" {
+		r.Detail = "skipped because the endpoint does not use https"
+		return r
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	if err != nil {
+		r.Err = errors.Wrap(err, "Failed the TLS handshake")
+		return r
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		r.Err = errors.New("The server did not present a certificate")
+		return r
+	}
+
+	cert := state.PeerCertificates[0]
+	r.Detail = fmt.Sprintf("%s, certificate valid until %s", tlsVersions[state.Version], cert.NotAfter.Format("2006-01-02"))
+	return r
+}
+
+// checkRequest makes an authenticated request to the endpoint and checks
+// the response for the latency, the clock skew, and the version compatibility
+func checkRequest(ctx infra.DnoteCtx, config infra.Config) []result {
+	var ret []result
+
+	auth := result{Name: "auth"}
+	apiKey, err := core.ReadAPIKey(ctx)
+	if err != nil {
+		auth.Err = errors.Wrap(err, "Failed to read the API key")
+	} else if apiKey == "" {
+		auth.Err = errors.New("Not logged in. Run `dnote login`")
+	} else {
+		auth.Detail = "an API key is set"
+	}
+
+	req, err := http.NewRequest("GET", ctx.APIEndpoint, nil)
+	if err != nil {
+		return append(ret, auth, result{Name: "latency", Err: errors.Wrap(err, "Failed to construct HTTP request")})
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", apiKey)
+	}
+	core.SetVersionHeaders(req)
+
+	client := core.NewHTTPClient(config)
+
+	requestedAt := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return append(ret, auth, result{Name: "latency", Err: errors.Wrap(err, "Failed to make request")})
+	}
+	receivedAt := time.Now()
+	resp.Body.Close()
+	log.Debugf("%s responded with %s, request id: %s", ctx.APIEndpoint, resp.Status, core.GetRequestID(resp))
+
+	if resp.StatusCode == http.StatusUnauthorized && apiKey != "" {
+		auth.Err = errors.New("The server rejected the API key. Run `dnote login` again")
+	}
+	ret = append(ret, auth)
+
+	ret = append(ret, result{
+		Name:   "latency",
+		Detail: fmt.Sprintf("%s round trip", receivedAt.Sub(requestedAt).Round(time.Millisecond)),
+	})
+
+	clock := result{Name: "clock"}
+	if skew, ok := core.GetClockSkew(resp, requestedAt, receivedAt); !ok {
+		clock.Detail = "skipped because the server did not send the time"
+	} else if core.IsClockSkewed(skew) {
+		clock.Err = errors.Errorf("The clock of this machine is off by %s from the server. Please check the system time", time.Duration(skew)*time.Second)
+	} else {
+		clock.Detail = fmt.Sprintf("off by %s from the server", time.Duration(skew)*time.Second)
+	}
+	ret = append(ret, clock)
+
+	version := result{Name: "version"}
+	if newer, err := core.CheckServerVersion(resp); err != nil {
+		version.Err = err
+	} else if newer {
+		version.Detail = fmt.Sprintf("supported, but a newer version is available. the server responded with %s", resp.Status)
+	} else if resp.StatusCode >= http.StatusInternalServerError {
+		version.Err = errors.Errorf("The server responded with %s", resp.Status)
+	} else {
+		version.Detail = fmt.Sprintf("the server responded with %s", resp.Status)
+	}
+	ret = append(ret, version)
+
+	return ret
+}
@@ -0,0 +1,122 @@
+package drafts
+
+import (
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * List the drafts of the notes that were not added
+ dnote drafts
+
+ * Continue the draft of a book
+ dnote add js
+
+ * Discard the draft of a book
+ dnote drafts remove js`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "drafts",
+		Short:   "List the drafts saved while writing notes in the editor",
+		Example: example,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: newListRun(ctx),
+	}
+
+	cmd.AddCommand(newRemoveCmd(ctx))
+
+	return cmd
+}
+
+func newListRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		config, err := core.ReadConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the config")
+		}
+
+		drafts, err := core.ReadDrafts(ctx, config.Drafts)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the drafts")
+		}
+
+		if len(drafts) == 0 {
+			log.Plain("no drafts. one is saved while a note is written in the editor\n")
+			return nil
+		}
+
+		for _, d := range drafts {
+			if log.Porcelain {
+				log.Fields(d.BookName, d.SavedOn, d.Content)
+				continue
+			}
+
+			bookName := d.BookName
+			if bookName == "" {
+				bookName = "(chosen by the rules)"
+			}
+
+			log.Printf("%s %s %s\n", bookName, time.Unix(d.SavedOn, 0).Format("2006-01-02 15:04"), core.GetTitle(d.Content))
+		}
+
+		return nil
+	}
+}
+
+func newRemoveCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <book name?>",
+		Aliases: []string{"rm"},
+		Short:   "Discard the draft of a book",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := core.ReadConfig(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the config")
+			}
+			var bookName string
+			if len(args) == 1 {
+				bookName = args[0]
+			}
+
+			d, ok, err := core.FindDraft(ctx, config.Drafts, bookName)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the drafts")
+			}
+			if !ok {
+				if bookName == "" {
+					return errors.New("There is no draft whose book is chosen by the rules")
+				}
+
+				return errors.Errorf("There is no draft of %s", bookName)
+			}
+
+			if err := core.RemoveDraft(ctx, config.Drafts, d); err != nil {
+				return errors.Wrap(err, "Failed to remove the draft")
+			}
+
+			log.Success("discarded the draft\n")
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,97 @@
+package dup
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Duplicate a note in the same book
+ dnote dup js 3
+
+ * Duplicate a note into another book
+ dnote dup js 3 typescript`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dup <book name> <note index> <target book name?>",
+		Short:   "Duplicate a note",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+		}
+		if idx < 0 || idx > len(book.Notes)-1 {
+			return errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+		}
+		source := book.Notes[idx]
+
+		targetBookName := bookName
+		if len(args) == 3 {
+			targetBookName = core.ResolveBookName(dnote, args[2])
+		}
+
+		targetBook, exists := dnote[targetBookName]
+		if !exists {
+			if err := core.ValidateBookName(targetBookName); err != nil {
+				return err
+			}
+
+			targetBook = core.NewBook(targetBookName)
+
+			if err := core.LogActionAddBook(ctx, targetBookName); err != nil {
+				return errors.Wrap(err, "Failed to log action")
+			}
+		}
+
+		ts := time.Now().Unix()
+		note := core.NewNote(source.Content, ts)
+		note.CopiedFrom = source.UUID
+
+		if err := core.LogActionAddNote(ctx, note.UUID, targetBookName, note.Content, ts); err != nil {
+			return errors.Wrap(err, "Failed to log action")
+		}
+
+		dnote[targetBookName] = core.GetUpdatedBook(targetBook, append(targetBook.Notes, note))
+		if err := core.WriteDnote(ctx, dnote); err != nil {
+			return errors.Wrap(err, "Failed to write dnote")
+		}
+
+		log.Successf("copied to %s as \033[%dm(%d)\033[0m\n", targetBookName, log.ColorYellow, len(dnote[targetBookName].Notes)-1)
+		return nil
+	}
+}
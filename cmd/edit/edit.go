@@ -52,7 +52,7 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return errors.Wrap(err, "Failed to read dnote")
 		}
 
-		targetBookName := args[0]
+		targetBookName := core.ResolveBookName(dnote, args[0])
 		targetIdx, err := strconv.Atoi(args[1])
 		if err != nil {
 			return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
@@ -81,6 +81,7 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			}
 
 		}
+		newContent = core.SanitizeContent(newContent)
 
 		if targetNote.Content == newContent {
 			return errors.New("Nothing changed")
@@ -88,7 +89,8 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 
 		ts := time.Now().Unix()
 
-		targetNote.Content = core.SanitizeContent(newContent)
+		targetNote.Content = newContent
+		targetNote.Title = core.GetTitle(targetNote.Content)
 		targetNote.EditedOn = ts
 		targetBook.Notes[targetIdx] = targetNote
 		dnote[targetBookName] = targetBook
@@ -106,6 +108,29 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 		log.Printf("new content: %s\n", newContent)
 		log.Success("edited the note\n")
 
+		lint(ctx, []string{targetNote.Content})
 		return nil
 	}
 }
+
+// lint prints the warnings of the linters enabled in the config. Failing to
+// run a linter only prints a warning because the note is already saved.
+func lint(ctx infra.DnoteCtx, contents []string) {
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		log.Warnf("failed to lint: %s\n", err.Error())
+		return
+	}
+
+	for _, c := range contents {
+		warnings, err := core.LintContent(config.Lint, c)
+		if err != nil {
+			log.Warnf("failed to lint: %s\n", err.Error())
+			return
+		}
+
+		for _, w := range warnings {
+			log.Warnf("%s: %s\n", w.Linter, w.Message)
+		}
+	}
+}
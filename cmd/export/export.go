@@ -0,0 +1,78 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var outputPath string
+
+var example = `
+ * Print the archive of a book
+ dnote export js
+
+ * Write the archive to a file
+ dnote export js -o js.json`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export <book name>",
+		Short:   "Export a book and its notes as JSON",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&outputPath, "output", "o", "", "The path of the file to write the archive to")
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		archive := core.NewBookArchive(book)
+		b, err := json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "Failed to marshal the archive into JSON")
+		}
+
+		if outputPath == "" {
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if err := ioutil.WriteFile(outputPath, b, 0644); err != nil {
+			return errors.Wrapf(err, "Failed to write the archive to %s", outputPath)
+		}
+
+		log.Successf("exported %d notes to %s\n", len(book.Notes), outputPath)
+		return nil
+	}
+}
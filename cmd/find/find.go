@@ -0,0 +1,547 @@
+package find
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var bookName string
+var countOnly bool
+var groupBy string
+var sortBy string
+var since string
+var until string
+var limit int
+var offset int
+var ref string
+var render bool
+var searchAll bool
+var templateText string
+var lang string
+var codeOnly bool
+var fuzzy bool
+var titleOnly bool
+var fieldsText string
+var print0 bool
+var onlyRead bool
+var onlyUnread bool
+var priorityText string
+
+var (
+	sortRelevance = "relevance"
+	sortCreated   = "created"
+	sortEdited    = "edited"
+)
+
+// dateLayout is the layout of the dates given to the since and until flags
+var dateLayout = "2006-01-02"
+
+var (
+	groupByBook  = "book"
+	groupByMonth = "month"
+	groupByTag   = "tag"
+)
+
+var example = `
+ * Find notes containing a keyword
+ dnote find closure
+
+ * Find notes in a book
+ dnote find closure -b js
+
+ * Count the notes about kubernetes
+ dnote find kubernetes --count
+
+ * Count the notes in each month
+ dnote find --group-by month
+
+ * Find notes referencing an issue and show the links to the issues
+ dnote find --ref WEB-123 --render
+
+ * Show the 10 most relevant notes edited this year
+ dnote find closure --sort relevance --since 2018-01-01 --limit 10
+
+ * Find the notes about docker left to read
+ dnote find docker --unread
+
+ * Find the notes labeled with a high priority
+ dnote find --priority high
+
+ * Find notes whose title contains a keyword
+ dnote find closure --title
+
+ * Find notes despite a typo in the keyword
+ dnote find kuberentes --fuzzy
+
+ * Print the Go code blocks of the notes about channels
+ dnote find channel --lang go --code-only
+
+ * Pass the titles and contents of the matching notes to another program
+ dnote find closure --fields title,content --print0 | xargs -0 -n1 echo
+
+ * Print the first line of each note with its book
+ dnote find closure --template '{{.Book}}: {{.Body | firstline}}'`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return errors.New("Incorrect number of argument")
+	}
+	if groupBy != "" && groupBy != groupByBook && groupBy != groupByMonth {
+		if groupBy == groupByTag {
+			return errors.New("Notes do not have tags")
+		}
+
+		return errors.Errorf("Cannot group by %s", groupBy)
+	}
+	if sortBy != "" && sortBy != sortRelevance && sortBy != sortCreated && sortBy != sortEdited {
+		return errors.Errorf("Cannot sort by %s", sortBy)
+	}
+	if codeOnly && templateText != "" {
+		return errors.New("Cannot use both code-only and template")
+	}
+	if (fieldsText != "" || print0) && (codeOnly || templateText != "") {
+		return errors.New("Cannot use fields or print0 with code-only or template")
+	}
+	if onlyRead && onlyUnread {
+		return errors.New("Cannot use both read and unread")
+	}
+	if priorityText != "" {
+		if _, err := core.ParsePriority(priorityText); err != nil {
+			return err
+		}
+	}
+	if limit < 0 || offset < 0 {
+		return errors.New("Limit and offset must not be negative")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "find <keyword?>",
+		Aliases: []string{"f", "search"},
+		Short:   "Find notes by keyword",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&bookName, "book", "b", "", "The book to search in")
+	f.BoolVarP(&countOnly, "count", "", false, "Print the number of matching notes")
+	f.StringVarP(&groupBy, "group-by", "", "", "Print the number of matching notes grouped by book or month")
+	f.StringVarP(&sortBy, "sort", "s", "", "Sort notes by relevance, created, or edited")
+	f.StringVarP(&since, "since", "", "", "Only find notes added or edited on or after the date (YYYY-MM-DD)")
+	f.StringVarP(&until, "until", "", "", "Only find notes added or edited before the date (YYYY-MM-DD)")
+	f.IntVarP(&limit, "limit", "", 0, "The maximum number of notes to print")
+	f.IntVarP(&offset, "offset", "", 0, "The number of notes to skip before printing")
+	f.StringVarP(&ref, "ref", "", "", "Only find notes referencing the issue, such as PROJ-123 or org/repo#45")
+	f.BoolVarP(&render, "render", "", false, "Print the links to the issues referenced in the notes")
+	f.BoolVarP(&searchAll, "all", "a", false, "Search all books even in a workspace")
+	f.StringVarP(&templateText, "template", "", "", "Print each note using a Go template")
+	f.StringVarP(&lang, "lang", "", "", "Only find notes with a code block in the language")
+	f.BoolVarP(&codeOnly, "code-only", "", false, "Print only the code blocks of the notes")
+	f.BoolVarP(&fuzzy, "fuzzy", "", false, "Find notes with words similar to the keyword, allowing typos")
+	f.BoolVarP(&titleOnly, "title", "", false, "Only search the titles of the notes")
+	f.StringVarP(&fieldsText, "fields", "", "", "Print the comma separated fields of each note, separated by tabs")
+	f.BoolVarP(&print0, "print0", "", false, "End each note with a null character instead of a newline")
+	f.BoolVarP(&onlyRead, "read", "", false, "Only find notes marked as read")
+	f.BoolVarP(&onlyUnread, "unread", "", false, "Only find notes not marked as read")
+	f.StringVarP(&priorityText, "priority", "", "", "Only find notes with the priority: none, low, med, or high")
+
+	return cmd
+}
+
+// match is a note matching the search along with its location
+type match struct {
+	BookName string
+	Index    int
+	Note     infra.Note
+	// Score is the number of occurrences of the keyword in the note
+	Score int
+	// Words are the words similar to the keyword in the fuzzy mode
+	Words []string
+}
+
+// window is the range of the last activity of the notes to find
+type window struct {
+	Start int64
+	End   int64
+}
+
+func (w window) contains(note infra.Note) bool {
+	ts := core.GetLastActivity(note)
+
+	if w.Start != 0 && ts < w.Start {
+		return false
+	}
+	if w.End != 0 && ts >= w.End {
+		return false
+	}
+
+	return true
+}
+
+func parseDate(date string) (int64, error) {
+	if date == "" {
+		return 0, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, date, time.Local)
+	if err != nil {
+		return 0, errors.Errorf("Invalid date %s. Use the format YYYY-MM-DD", date)
+	}
+
+	return t.Unix(), nil
+}
+
+func getWindow() (window, error) {
+	var ret window
+
+	start, err := parseDate(since)
+	if err != nil {
+		return ret, err
+	}
+	end, err := parseDate(until)
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Start = start
+	ret.End = end
+
+	return ret, nil
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		var keyword string
+		if len(args) == 1 {
+			keyword = args[0]
+		}
+
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		var inWorkspace bool
+		if bookName == "" && !searchAll {
+			workspace, err := core.ReadWorkspace()
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the workspace")
+			}
+
+			bookName = workspace.Book
+			inWorkspace = bookName != ""
+		}
+
+		if bookName != "" {
+			bookName = core.ResolveBookName(dnote, bookName)
+
+			// The book of a workspace is only created by the first note added to
+			// it, so it is searched as an empty book until then
+			if _, ok := dnote[bookName]; !ok && !inWorkspace {
+				return errors.Errorf("Book %s does not exist", bookName)
+			}
+		}
+
+		w, err := getWindow()
+		if err != nil {
+			return err
+		}
+
+		matches := search(dnote, keyword, w)
+		sortMatches(matches)
+
+		if countOnly {
+			log.Fields(len(matches))
+			return nil
+		}
+		if groupBy != "" {
+			printGroups(matches)
+			return nil
+		}
+
+		var trackers map[string]string
+		if render {
+			config, err := core.ReadConfig(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the config")
+			}
+
+			trackers = config.Trackers
+		}
+
+		if codeOnly {
+			printCodeBlocks(paginate(matches))
+			return nil
+		}
+		if templateText != "" {
+			t, err := core.NewNoteTemplate(templateText)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range paginate(matches) {
+				if err := core.PrintNoteView(t, core.NewNoteView(m.BookName, m.Index, m.Note)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		if fieldsText != "" || print0 {
+			fields, err := core.ParseNoteFields(fieldsText)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range paginate(matches) {
+				core.PrintNoteFields(core.NewNoteView(m.BookName, m.Index, m.Note), fields, print0)
+			}
+
+			return nil
+		}
+
+		printMatches(paginate(matches), trackers)
+		return nil
+	}
+}
+
+// search returns the notes in the window whose content contains the keyword,
+// ignoring case
+func search(dnote infra.Dnote, keyword string, w window) []match {
+	var ret []match
+
+	keyword = strings.ToLower(keyword)
+	priority, _ := core.ParsePriority(priorityText)
+
+	for name, book := range dnote {
+		if bookName != "" && name != bookName {
+			continue
+		}
+
+		for i, note := range book.Notes {
+			if !w.contains(note) {
+				continue
+			}
+			if (onlyRead && note.ReadOn == 0) || (onlyUnread && note.ReadOn != 0) {
+				continue
+			}
+			if priorityText != "" && note.Priority != priority {
+				continue
+			}
+
+			text := note.Content
+			if titleOnly {
+				text = note.Title
+			}
+
+			var words []string
+			content := strings.ToLower(text)
+			if fuzzy && keyword != "" {
+				words = core.FuzzyMatch(text, keyword)
+				if words == nil {
+					continue
+				}
+			} else if !strings.Contains(content, keyword) {
+				continue
+			}
+			if ref != "" && !core.HasReference(note.Content, ref) {
+				continue
+			}
+			if lang != "" && len(core.GetCodeBlocksByLang(note.Content, lang)) == 0 {
+				continue
+			}
+
+			var score int
+			if words != nil {
+				for _, w := range words {
+					score += strings.Count(text, w)
+				}
+			} else if keyword != "" {
+				score = strings.Count(content, keyword)
+			}
+
+			ret = append(ret, match{BookName: name, Index: i, Note: note, Score: score, Words: words})
+		}
+	}
+
+	return ret
+}
+
+// sortMatches sorts the matches by the sort flag. Ties are broken by the book
+// name and the index so that the order is deterministic.
+func sortMatches(matches []match) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+
+		switch sortBy {
+		case sortRelevance:
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			if core.GetLastActivity(a.Note) != core.GetLastActivity(b.Note) {
+				return core.GetLastActivity(a.Note) > core.GetLastActivity(b.Note)
+			}
+		case sortCreated:
+			if a.Note.AddedOn != b.Note.AddedOn {
+				return a.Note.AddedOn > b.Note.AddedOn
+			}
+		case sortEdited:
+			if core.GetLastActivity(a.Note) != core.GetLastActivity(b.Note) {
+				return core.GetLastActivity(a.Note) > core.GetLastActivity(b.Note)
+			}
+		}
+
+		if a.BookName != b.BookName {
+			return core.LessBookName(a.BookName, b.BookName)
+		}
+
+		return a.Index < b.Index
+	})
+}
+
+// paginate returns the matches in the page given by the offset and limit flags
+func paginate(matches []match) []match {
+	if offset >= len(matches) {
+		return nil
+	}
+
+	matches = matches[offset:]
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+func getGroupKey(m match) string {
+	if groupBy == groupByMonth {
+		// Group by the same time that --since and --until filter on
+		return time.Unix(core.GetLastActivity(m.Note), 0).Format("2006-01")
+	}
+
+	return m.BookName
+}
+
+func printGroups(matches []match) {
+	counts := map[string]int{}
+	var keys []string
+
+	for _, m := range matches {
+		key := getGroupKey(m)
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		counts[key]++
+	}
+
+	core.SortBookNames(keys)
+
+	for _, key := range keys {
+		if log.Porcelain {
+			log.Fields(key, counts[key])
+			continue
+		}
+
+		log.Printf("%s \033[%dm(%d)\033[0m\n", key, log.ColorYellow, counts[key])
+	}
+}
+
+func printMatches(matches []match, trackers map[string]string) {
+	for _, m := range matches {
+		if log.Porcelain {
+			log.Fields(m.BookName, m.Index, m.Note.Content)
+			continue
+		}
+
+		log.Raw(fmt.Sprintf("  %s \033[%dm(%d)\033[0m %s%s\n", m.BookName, log.ColorYellow, m.Index, core.PriorityMarker(m.Note.Priority), highlight(m.Note.Title, m.Words)))
+
+		if render {
+			printReferences(m.Note, trackers)
+		}
+	}
+}
+
+// highlight colors the words of the content that are among the given words.
+// The content is scanned once, splitting the words as core.FuzzyMatch does, so
+// that a word within another word or within an inserted color code is never
+// colored.
+func highlight(content string, words []string) string {
+	if len(words) == 0 {
+		return content
+	}
+
+	set := map[string]bool{}
+	for _, w := range words {
+		set[w] = true
+	}
+
+	isWordRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	var buf bytes.Buffer
+	runes := []rune(content)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			buf.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+
+		w := string(runes[i:j])
+		if set[w] {
+			fmt.Fprintf(&buf, "\033[%dm%s\033[0m", log.ColorGreen, w)
+		} else {
+			buf.WriteString(w)
+		}
+		i = j
+	}
+
+	return buf.String()
+}
+
+// printCodeBlocks prints the code blocks of the notes as is, so that they can
+// be piped into a file or the clipboard. Only the blocks in the language are
+// printed if the lang flag is given.
+func printCodeBlocks(matches []match) {
+	for _, m := range matches {
+		// The code is printed as it is so that it can be piped to other commands
+		for _, b := range core.GetCodeBlocksByLang(m.Note.Content, lang) {
+			fmt.Println(b.Code)
+		}
+	}
+}
+
+// printReferences prints the links to the issues referenced in the note
+func printReferences(note infra.Note, trackers map[string]string) {
+	for _, r := range core.GetReferences(note.Content) {
+		u := r.URL(trackers)
+		if u == "" {
+			continue
+		}
+
+		log.Raw(fmt.Sprintf("      \033[%dm%s\033[0m %s\n", log.ColorBlue, r.String(), u))
+	}
+}
@@ -0,0 +1,143 @@
+package help
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultPager is the pager used to show a topic if PAGER is not set
+var defaultPager = "less -FRX"
+
+var example = `
+ * Show the help for a command
+ dnote help add
+
+ * List the guides
+ dnote help topics
+
+ * Read the guide about the sync
+ dnote help topics sync`
+
+// NewCmd returns the help command. It shows the help for the commands like
+// the default help command of cobra, and the guides as its subcommand.
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "help [command]",
+		Short:   "Help about any command",
+		Example: example,
+		Run: func(c *cobra.Command, args []string) {
+			cmd, _, err := c.Root().Find(args)
+			if cmd == nil || err != nil {
+				c.Printf("Unknown help topic %#q\n", args)
+				c.Root().Usage()
+				return
+			}
+
+			cmd.InitDefaultHelpFlag()
+			cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newTopicsCmd())
+
+	return cmd
+}
+
+func newTopicsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "topics [name]",
+		Short: "Read the guides about the sync, the conflicts, and scripting",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				printTopics()
+				return nil
+			}
+
+			t, ok := findTopic(args[0])
+			if !ok {
+				return errors.Errorf("Topic %s does not exist. Run `dnote help topics` to list the topics", args[0])
+			}
+
+			if err := page(strings.TrimLeft(t.Content, "\n")); err != nil {
+				return errors.Wrap(err, "Failed to show the topic")
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func findTopic(name string) (topic, bool) {
+	for _, t := range topics {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+
+	return topic{}, false
+}
+
+func printTopics() {
+	for _, t := range topics {
+		if log.Porcelain {
+			log.Fields(t.Name, t.Summary)
+			continue
+		}
+
+		log.Plainf("%-12s %s\n", t.Name, t.Summary)
+	}
+
+	log.Plain("\nrun `dnote help topics [name]` to read a topic\n")
+}
+
+// page shows the content through the pager if the output is a terminal, and
+// prints it as is otherwise or if the pager cannot be run
+func page(content string) error {
+	if !isTerminal(os.Stdout) {
+		fmt.Print(content)
+		return nil
+	}
+
+	pager := os.Getenv("PAGER")
+	if strings.TrimSpace(pager) == "" {
+		pager = defaultPager
+	}
+
+	args := strings.Fields(pager)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		fmt.Print(content)
+		return nil
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
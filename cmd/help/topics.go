@@ -0,0 +1,143 @@
+package help
+
+// topic is a long-form guide shown by `dnote help topics`
+type topic struct {
+	Name    string
+	Summary string
+	Content string
+}
+
+// topics are listed in this order
+var topics = []topic{
+	{Name: "sync", Summary: "How the notes are synced with the server", Content: syncTopic},
+	{Name: "conflicts", Summary: "What happens when the same notes change on two machines", Content: conflictsTopic},
+	{Name: "scripting", Summary: "Recipes for using dnote from scripts", Content: scriptingTopic},
+}
+
+var syncTopic = `
+SYNC
+
+Every change made locally, such as adding, editing, or removing a note or a
+book, is appended to the action log in the dnote directory, in addition to
+being applied to the notes. The notes on this machine are always complete, so
+dnote works offline, and nothing is sent until you run:
+
+    dnote sync
+
+A sync makes a single request to the server with two things:
+
+  * the actions logged since the last sync, compressed
+  * the bookmark, which marks how far this machine has read the changes made
+    on the server
+
+The server records the actions and responds with the actions made by the other
+machines after the bookmark, as well as a new bookmark. Those actions are then
+applied to the local notes in order, the action log is cleared, and the new
+bookmark is saved.
+
+Before anything is sent, a snapshot of the notes, the action log, and the
+bookmark is taken. If a sync goes wrong, restore the state before it with:
+
+    dnote snapshots list
+    dnote snapshots rollback 1
+
+If the sync is aborted with Ctrl-C or --timeout before the server accepts the
+local changes, nothing is synced. Once the server has accepted them, the
+response is still downloaded, and the actions from the server are kept to be
+applied by the next sync. If applying them fails or is aborted, the local notes
+are left as they were before the sync.
+
+To see what a sync is about to send, and what the last sync downloaded, run:
+
+    dnote diff
+
+On a metered connection, set metered under sync in dnoterc to be asked before
+a large download. The size is checked before anything is uploaded, so if the
+download is declined, nothing is synced and the local changes are kept.
+
+If the server rejects the version of the CLI, the sync stops before applying
+any change and asks you to run dnote upgrade.
+`
+
+var conflictsTopic = `
+CONFLICTS
+
+The server does not merge notes. It keeps the actions from every machine in the
+order they were received, and each machine applies the actions from the others
+in that order. The result is that the last write wins:
+
+  * If a note is edited on two machines, the edit that reaches the server last
+    is applied last, and it replaces the other one everywhere.
+  * If a note is edited on one machine and removed on another, whichever
+    reaches the server last wins. An edit to a note that was removed is
+    ignored.
+  * If a book with the same name is added on two machines, they become the same
+    book. Book names are matched ignoring case.
+
+To reduce the chance of a conflict, sync before editing a note on a machine
+that has not been used for a while, and sync right after.
+
+The action log can be inspected before a sync with dnote diff. If a sync
+replaced a note you wanted to keep, dnote diff shows the lines the sync
+replaced. An older content can be recovered from a snapshot, unless the local
+changes in the snapshot have been synced since:
+
+    dnote snapshots rollback 1
+    dnote find [keyword]
+
+Copy the content to keep, and run dnote sync again to bring the notes back up
+to date before writing it back.
+
+A command that is aborted because of a conflict with the state of the server
+exits with 2, so that scripts can tell it apart from other failures.
+`
+
+var scriptingTopic = `
+SCRIPTING
+
+Give --porcelain to print the results as tab-separated fields in a stable
+format, and to suppress the other output. Errors are printed to stderr
+prefixed with "error:". Use --quiet to only suppress the output.
+
+The exit codes tell the failures apart:
+
+    0  success
+    1  the command failed, usually due to an invalid input
+    3  login is required
+    4  the server could not be reached or returned an error
+
+Add a note from the output of a command:
+
+    dnote add git -c "$(git log -1 --format=%B)"
+
+Add many notes at once, one JSON object per line:
+
+    dnote add js --jsonl < notes.jsonl
+
+Print the chosen fields of the notes, separated by NUL so that notes spanning
+several lines can be read safely:
+
+    dnote find docker --fields book,index,title --print0 |
+      while IFS= read -r -d '' line; do echo "$line"; done
+
+Format the notes with a template:
+
+    dnote ls js --template '{{.Index}}: {{.Title}}'
+
+Count the notes matching a keyword:
+
+    dnote find kubectl --count
+
+Back up a book and restore it on another machine:
+
+    dnote export js -o js.json
+    dnote import js.json
+
+Sync from cron, logging why a sync failed:
+
+    */30 * * * * dnote sync --quiet --timeout 2m --log-file || echo "sync failed with $?"
+
+When reporting a problem, attach the report of:
+
+    dnote doctor --network --porcelain
+`
@@ -0,0 +1,249 @@
+package importcmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var targetBookName string
+var from string
+var applyRules bool
+
+var (
+	formatArchive    = "archive"
+	formatLegacyYAML = "legacy-yaml"
+)
+
+var example = `
+ * Import a book exported with 'dnote export'
+ dnote import js.json
+
+ * Import the notes into a different book
+ dnote import js.json -b javascript
+
+ * Import notes written by dnote v0.1 in YAML format
+ dnote import --from legacy-yaml notes.yaml
+
+ * Sort the imported notes into books using the rules in dnoterc
+ dnote import js.json --rules`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "import <path>",
+		Short:   "Import notes from a file",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&targetBookName, "book", "b", "", "The book to import the notes into")
+	f.StringVarP(&from, "from", "", formatArchive, "The format of the file (archive or legacy-yaml)")
+	f.BoolVarP(&applyRules, "rules", "", false, "Import the notes matching a rule in dnoterc into the book of the rule")
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s", path)
+		}
+
+		var books map[string][]string
+		switch from {
+		case formatArchive:
+			books, err = parseArchive(b)
+		case formatLegacyYAML:
+			books, err = parseLegacyYAML(b)
+		default:
+			return errors.Errorf("Unsupported format %s", from)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse %s", path)
+		}
+
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		if targetBookName != "" {
+			var contents []string
+			for _, name := range getBookNames(books) {
+				contents = append(contents, books[name]...)
+			}
+
+			books = map[string][]string{targetBookName: contents}
+		}
+
+		if applyRules {
+			config, err := core.ReadConfig(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the config")
+			}
+
+			books, err = sortByRules(books, config.Rules)
+			if err != nil {
+				return errors.Wrap(err, "Failed to apply the rules")
+			}
+		}
+
+		bookNames := getBookNames(books)
+		for _, bookName := range bookNames {
+			if _, ok := dnote[core.ResolveBookName(dnote, bookName)]; ok {
+				continue
+			}
+			if err := core.ValidateBookName(bookName); err != nil {
+				return errors.Wrap(err, "Cannot create a book for the imported notes. Use --book to import them to another book")
+			}
+		}
+
+		for _, bookName := range bookNames {
+			contents := books[bookName]
+			bookName = core.ResolveBookName(dnote, bookName)
+
+			// Notes exported by dnote v0.1 may have been imported already
+			if from == formatLegacyYAML {
+				contents = dedupe(dnote[bookName], contents)
+			}
+			if len(contents) == 0 {
+				log.Plainf("%s is up-to-date\n", bookName)
+				continue
+			}
+
+			count, err := core.ImportNotes(ctx, dnote, bookName, contents)
+			if err != nil {
+				return errors.Wrap(err, "Failed to import notes")
+			}
+
+			log.Successf("imported %d notes to %s\n", count, bookName)
+		}
+
+		if err := core.WriteDnote(ctx, dnote); err != nil {
+			return errors.Wrap(err, "Failed to write dnote")
+		}
+
+		return nil
+	}
+}
+
+// parseArchive returns the note contents in the archive keyed by book name
+func parseArchive(b []byte) (map[string][]string, error) {
+	var archive core.BookArchive
+	if err := json.Unmarshal(b, &archive); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal the archive")
+	}
+	if archive.Version != core.ArchiveVersion {
+		return nil, errors.Errorf("Unsupported archive version %d", archive.Version)
+	}
+	if archive.Book.Name == "" && targetBookName == "" {
+		return nil, errors.New("Book name is missing in the archive")
+	}
+
+	var contents []string
+	for _, note := range archive.Book.Notes {
+		contents = append(contents, note.Content)
+	}
+
+	return map[string][]string{archive.Book.Name: contents}, nil
+}
+
+// parseLegacyYAML returns the note contents in the YAML file used by dnote
+// v0.1, which maps each channel to a list of notes, keyed by book name
+func parseLegacyYAML(b []byte) (map[string][]string, error) {
+	var channels map[string][]string
+	if err := yaml.Unmarshal(b, &channels); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal the YAML")
+	}
+
+	ret := map[string][]string{}
+	for channel, notes := range channels {
+		for _, note := range notes {
+			content := core.SanitizeContent(note)
+			if content == "" {
+				continue
+			}
+
+			ret[channel] = append(ret[channel], content)
+		}
+	}
+
+	return ret, nil
+}
+
+// sortByRules moves the contents matching a rule into the book of the rule.
+// Other contents stay in their books.
+func sortByRules(books map[string][]string, rules []infra.Rule) (map[string][]string, error) {
+	ret := map[string][]string{}
+
+	for _, bookName := range getBookNames(books) {
+		for _, c := range books[bookName] {
+			rule, ok, err := core.MatchRule(rules, c)
+			if err != nil {
+				return nil, err
+			}
+
+			name := bookName
+			if ok {
+				name = rule.Book
+			}
+
+			ret[name] = append(ret[name], c)
+		}
+	}
+
+	return ret, nil
+}
+
+// getBookNames returns the names of the books in sorted order, so that the
+// notes are imported in the same order every time
+func getBookNames(books map[string][]string) []string {
+	ret := make([]string, 0, len(books))
+	for name := range books {
+		ret = append(ret, name)
+	}
+	core.SortBookNames(ret)
+
+	return ret
+}
+
+// dedupe filters out the contents that already exist in the book or appear
+// more than once
+func dedupe(book infra.Book, contents []string) []string {
+	var ret []string
+
+	seen := map[string]bool{}
+	for _, note := range book.Notes {
+		seen[note.Content] = true
+	}
+
+	for _, content := range contents {
+		if seen[content] {
+			continue
+		}
+
+		seen[content] = true
+		ret = append(ret, content)
+	}
+
+	return ret
+}
@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Check the notes in a book with the linters in dnoterc
+ dnote lint js
+
+ * Check the notes in all books
+ dnote lint`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lint <book name?>",
+		Short:   "Check the notes with the linters in the config",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		config, err := core.ReadConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the config")
+		}
+		if len(config.Lint.Linters) == 0 {
+			return errors.New("No linters are enabled. Set lint.linters in dnoterc")
+		}
+
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		var bookNames []string
+		if len(args) == 1 {
+			name := core.ResolveBookName(dnote, args[0])
+			if _, ok := dnote[name]; !ok {
+				return errors.Errorf("Book %s does not exist", name)
+			}
+
+			bookNames = []string{name}
+		} else {
+			for name := range dnote {
+				bookNames = append(bookNames, name)
+			}
+			core.SortBookNames(bookNames)
+		}
+
+		var count int
+		for _, bookName := range bookNames {
+			// Books named before the names were validated are reported so
+			// that their notes can be moved to a valid book
+			if err := core.ValidateBookName(bookName); err != nil {
+				count++
+
+				if log.Porcelain {
+					log.Fields(bookName, "-", "name", err.Error())
+				} else {
+					log.Warnf("%s name: %s\n", bookName, err.Error())
+				}
+			}
+
+			for i, note := range dnote[bookName].Notes {
+				warnings, err := core.LintContent(config.Lint, note.Content)
+				if err != nil {
+					return errors.Wrap(err, "Failed to lint")
+				}
+
+				for _, w := range warnings {
+					count++
+
+					if log.Porcelain {
+						log.Fields(bookName, i, w.Linter, w.Message)
+						continue
+					}
+
+					log.Warnf("%s \033[%dm(%d)\033[0m %s: %s\n", bookName, log.ColorYellow, i, w.Linter, w.Message)
+				}
+			}
+		}
+
+		if count == 0 {
+			log.Success("no problems found\n")
+			return nil
+		}
+
+		log.Plainf("%d problems found\n", count)
+		return nil
+	}
+}
@@ -33,7 +33,7 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 		log.Plain("Welcome to Dnote Cloud :)\n\n")
 		log.Plain("A home for your engineering microlessons\n")
 		log.Plain("You can register at https://dnote.io\n\n")
-		log.Printf("API key: ")
+		log.Askf("API key: ")
 
 		var apiKey string
 		fmt.Scanln(&apiKey)
@@ -42,15 +42,9 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return errors.New("Empty API key")
 		}
 
-		config, err := core.ReadConfig(ctx)
+		err := core.WriteAPIKey(ctx, apiKey)
 		if err != nil {
-			return err
-		}
-
-		config.APIKey = apiKey
-		err = core.WriteConfig(ctx, config)
-		if err != nil {
-			return err
+			return errors.Wrap(err, "Failed to save the API key")
 		}
 
 		log.Success("configured\n")
@@ -0,0 +1,46 @@
+package logout
+
+import (
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Remove the API key from this machine
+ dnote logout`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "logout",
+		Short:   "Logout from dnote server",
+		Example: example,
+		RunE:    newRun(ctx),
+	}
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		apiKey, err := core.ReadAPIKey(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the API key")
+		}
+
+		if apiKey == "" {
+			log.Plain("not logged in\n")
+			return nil
+		}
+
+		if err := core.ClearAPIKey(ctx); err != nil {
+			return errors.Wrap(err, "Failed to clear the API key")
+		}
+
+		log.Success("logged out\n")
+
+		return nil
+	}
+}
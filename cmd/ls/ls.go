@@ -1,28 +1,66 @@
 package ls
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/dnote-io/cli/core"
 	"github.com/dnote-io/cli/infra"
 	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var treeMode bool
+var listAll bool
+var recentCount int
+var days int
+var templateText string
+var fieldsText string
+var print0 bool
+var onlyUnread bool
+
 var example = `
  * List all books
  dnote ls
 
  * List notes in a book
  dnote ls javascript
+
+ * List all books when in a workspace
+ dnote ls --all
+
+ * Show every book with its most recent notes
+ dnote ls --tree
+
+ * Only show notes added or edited in the last 7 days
+ dnote ls --tree --days 7
+
+ * List the notes in a book not marked as read
+ dnote ls javascript --unread
+
+ * Print the notes in a book using a template
+ dnote ls javascript --template '{{.Index}} {{.Body | firstline | truncate 40}}'
+
+ * Print the index and content of the notes in a book, ending each with a null character
+ dnote ls javascript --fields index,content --print0
  `
 
 func preRun(cmd *cobra.Command, args []string) error {
 	if len(args) > 1 {
 		return errors.New("Incorrect number of argument")
 	}
+	if (fieldsText != "" || print0) && templateText != "" {
+		return errors.New("Cannot use fields or print0 with template")
+	}
+	if recentCount < 0 || days < 0 {
+		return errors.New("The number of notes and days must not be negative")
+	}
 
 	return nil
 }
@@ -37,6 +75,16 @@ func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
 		PreRunE: preRun,
 	}
 
+	f := cmd.Flags()
+	f.BoolVarP(&treeMode, "tree", "t", false, "Show books with their most recent notes")
+	f.BoolVarP(&listAll, "all", "a", false, "List all books even in a workspace")
+	f.IntVarP(&recentCount, "notes", "n", 3, "The number of notes to show for each book in the tree")
+	f.IntVarP(&days, "days", "", 0, "Only show notes added or edited in this many days in the tree")
+	f.StringVarP(&templateText, "template", "", "", "Print each note in the book using a Go template")
+	f.StringVarP(&fieldsText, "fields", "", "", "Print the comma separated fields of each note in the book, separated by tabs")
+	f.BoolVarP(&print0, "print0", "", false, "End each note in the book with a null character instead of a newline")
+	f.BoolVarP(&onlyUnread, "unread", "", false, "Only list the notes in the book not marked as read")
+
 	return cmd
 }
 
@@ -47,6 +95,25 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return errors.Wrap(err, "Failed to read dnote")
 		}
 
+		if treeMode {
+			if err := printTree(dnote, args); err != nil {
+				return errors.Wrap(err, "Failed to print the tree")
+			}
+
+			return nil
+		}
+
+		if len(args) == 0 && !listAll {
+			workspace, err := core.ReadWorkspace()
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the workspace")
+			}
+
+			if workspace.Book != "" {
+				args = []string{workspace.Book}
+			}
+		}
+
 		if len(args) == 0 {
 			if err := printBooks(dnote); err != nil {
 				return errors.Wrap(err, "Failed to print books")
@@ -55,7 +122,7 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return nil
 		}
 
-		bookName := args[0]
+		bookName := core.ResolveBookName(dnote, args[0])
 		if err := printNotes(dnote, bookName); err != nil {
 			return errors.Wrapf(err, "Failed to print notes for the book %s", bookName)
 		}
@@ -68,13 +135,27 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 type bookInfo struct {
 	BookName  string
 	NoteCount int
+	// Size is the total size of the contents of the notes in bytes
+	Size int64
+	// LastEdited is the timestamp of the most recent activity in the book
+	LastEdited int64
 }
 
 func getBookInfos(dnote infra.Dnote) []bookInfo {
 	var ret []bookInfo
 
 	for bookName, book := range dnote {
-		ret = append(ret, bookInfo{BookName: bookName, NoteCount: len(book.Notes)})
+		info := bookInfo{BookName: bookName, NoteCount: len(book.Notes)}
+
+		for _, note := range book.Notes {
+			info.Size += int64(len(note.Content))
+
+			if ts := core.GetLastActivity(note); ts > info.LastEdited {
+				info.LastEdited = ts
+			}
+		}
+
+		ret = append(ret, info)
 	}
 
 	return ret
@@ -85,23 +166,187 @@ func printBooks(dnote infra.Dnote) error {
 
 	// Show books with more notes first
 	sort.SliceStable(infos, func(i, j int) bool {
-		return infos[i].NoteCount > infos[j].NoteCount
+		if infos[i].NoteCount != infos[j].NoteCount {
+			return infos[i].NoteCount > infos[j].NoteCount
+		}
+
+		return core.LessBookName(infos[i].BookName, infos[j].BookName)
 	})
 
+	if log.Porcelain {
+		for _, info := range infos {
+			log.Fields(info.BookName, info.NoteCount, info.Size, info.LastEdited)
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "BOOK\tNOTES\tSIZE\tLAST EDITED")
 	for _, info := range infos {
-		log.Printf("%s \033[%dm(%d)\033[0m\n", info.BookName, log.ColorYellow, info.NoteCount)
+		lastEdited := "-"
+		if info.LastEdited > 0 {
+			lastEdited = time.Unix(info.LastEdited, 0).Format("2006-01-02")
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", info.BookName, info.NoteCount, utils.FormatSize(info.Size), lastEdited)
+	}
+
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "Failed to write the table")
+	}
+
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line != "" {
+			log.Plain(line)
+		}
 	}
 
 	return nil
 }
 
 func printNotes(dnote infra.Dnote, bookName string) error {
+	book := dnote[bookName]
+
+	if templateText != "" {
+		t, err := core.NewNoteTemplate(templateText)
+		if err != nil {
+			return err
+		}
+
+		for i, note := range book.Notes {
+			if onlyUnread && note.ReadOn != 0 {
+				continue
+			}
+
+			if err := core.PrintNoteView(t, core.NewNoteView(bookName, i, note)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if fieldsText != "" || print0 {
+		fields, err := core.ParseNoteFields(fieldsText)
+		if err != nil {
+			return err
+		}
+
+		for i, note := range book.Notes {
+			if onlyUnread && note.ReadOn != 0 {
+				continue
+			}
+
+			core.PrintNoteFields(core.NewNoteView(bookName, i, note), fields, print0)
+		}
+
+		return nil
+	}
+
 	log.Infof("on book %s\n", bookName)
 
-	book := dnote[bookName]
+	for i, note := range book.Notes {
+		if onlyUnread && note.ReadOn != 0 {
+			continue
+		}
+
+		if log.Porcelain {
+			log.Fields(bookName, i, note.Content)
+			continue
+		}
+
+		log.Raw(fmt.Sprintf("  \033[%dm(%d)\033[0m %s%s\n", log.ColorYellow, i, core.PriorityMarker(note.Priority), note.Title))
+	}
+
+	return nil
+}
+
+// treeNote is a note to be printed in the tree along with its index in the book
+type treeNote struct {
+	Index int
+	Note  infra.Note
+}
+
+// getRecentNotes returns at most count notes in the book, most recently active
+// first, that were active after the given timestamp
+func getRecentNotes(book infra.Book, since int64, count int) []treeNote {
+	var ret []treeNote
 
 	for i, note := range book.Notes {
-		fmt.Printf("  \033[%dm(%d)\033[0m %s\n", log.ColorYellow, i, note.Content)
+		if core.GetLastActivity(note) < since {
+			continue
+		}
+
+		ret = append(ret, treeNote{Index: i, Note: note})
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return core.GetLastActivity(ret[i].Note) > core.GetLastActivity(ret[j].Note)
+	})
+
+	if len(ret) > count {
+		ret = ret[:count]
+	}
+
+	return ret
+}
+
+// getPreview returns the title truncated to the given number of characters
+func getPreview(title string, length int) string {
+	runes := []rune(title)
+	if len(runes) > length {
+		return string(runes[:length]) + "..."
+	}
+
+	return title
+}
+
+func printTree(dnote infra.Dnote, bookNames []string) error {
+	var since int64
+	if days > 0 {
+		since = time.Now().Unix() - int64(days)*86400
+	}
+
+	if len(bookNames) == 0 {
+		for bookName := range dnote {
+			bookNames = append(bookNames, bookName)
+		}
+		core.SortBookNames(bookNames)
+	}
+
+	for _, bookName := range bookNames {
+		bookName = core.ResolveBookName(dnote, bookName)
+		book, ok := dnote[bookName]
+		if !ok {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		notes := getRecentNotes(book, since, recentCount)
+		if days > 0 && len(notes) == 0 {
+			continue
+		}
+
+		if log.Porcelain {
+			for _, n := range notes {
+				log.Fields(bookName, n.Index, getPreview(n.Note.Title, 60))
+			}
+
+			continue
+		}
+
+		log.Printf("%s \033[%dm(%d)\033[0m\n", bookName, log.ColorYellow, len(book.Notes))
+
+		for i, n := range notes {
+			branch := "├─"
+			if i == len(notes)-1 {
+				branch = "└─"
+			}
+
+			log.Raw(fmt.Sprintf("    %s \033[%dm(%d)\033[0m %s\n", branch, log.ColorYellow, n.Index, getPreview(n.Note.Title, 60)))
+		}
 	}
 
 	return nil
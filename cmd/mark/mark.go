@@ -0,0 +1,107 @@
+package mark
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Mark a note as read
+ dnote mark read js 3
+
+ * Mark all notes in a book as unread
+ dnote mark unread js
+
+ * List the notes left to read in a book
+ dnote ls js --unread`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "mark",
+		Short:   "Mark notes as read or unread",
+		Example: example,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "read <book name> <note index?>",
+		Short:   "Mark a note, or all notes in a book, as read",
+		PreRunE: preRun,
+		RunE:    newRun(ctx, true),
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "unread <book name> <note index?>",
+		Short:   "Mark a note, or all notes in a book, as unread",
+		PreRunE: preRun,
+		RunE:    newRun(ctx, false),
+	})
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx, read bool) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		indices := make([]int, len(book.Notes))
+		for i := range book.Notes {
+			indices[i] = i
+		}
+		if len(args) == 2 {
+			idx, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+			}
+			if idx < 0 || idx > len(book.Notes)-1 {
+				return errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+			}
+
+			indices = []int{idx}
+		}
+
+		var readOn int64
+		if read {
+			readOn = time.Now().Unix()
+		}
+
+		// The read state is not synced, so no action is logged
+		for _, idx := range indices {
+			book.Notes[idx].ReadOn = readOn
+		}
+		dnote[bookName] = book
+
+		if err := core.WriteDnote(ctx, dnote); err != nil {
+			return errors.Wrap(err, "Failed to write dnote")
+		}
+
+		state := "unread"
+		if read {
+			state = "read"
+		}
+
+		log.Successf("marked %d notes in %s as %s\n", len(indices), bookName, state)
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package priority
+
+import (
+	"strconv"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Label a note with a high priority
+ dnote priority js 3 high
+
+ * Remove the label
+ dnote priority js 3 none
+
+ * Find the notes labeled with a high priority
+ dnote find --priority high`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "priority <book name> <note index> <none|low|med|high>",
+		Short:   "Set the priority of a note",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		priority, err := core.ParsePriority(args[2])
+		if err != nil {
+			return err
+		}
+
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+		}
+		if idx < 0 || idx > len(book.Notes)-1 {
+			return errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+		}
+
+		note := book.Notes[idx]
+		if note.Priority == priority {
+			log.Plain("the priority is already set\n")
+			return nil
+		}
+
+		note.Priority = priority
+		book.Notes[idx] = note
+		dnote[bookName] = book
+
+		if err := core.WriteDnote(ctx, dnote); err != nil {
+			return errors.Wrap(err, "Failed to write dnote")
+		}
+
+		if priority == core.PriorityNone {
+			log.Successf("removed the priority of the note\n")
+			return nil
+		}
+
+		log.Successf("set the priority of the note to %s\n", priority)
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+package related
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var limit int
+
+var example = `
+ * Show the notes related to the note at index 3 in the js book
+ dnote related js 3
+
+ * Show up to 10 related notes
+ dnote related js 3 --limit 10`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "related <book name> <note index>",
+		Short:   "Show the notes related to a note",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.IntVarP(&limit, "limit", "", 5, "The maximum number of related notes to show")
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		bookName := core.ResolveBookName(dnote, args[0])
+		book, exists := dnote[bookName]
+		if !exists {
+			return errors.Errorf("Book %s does not exist", bookName)
+		}
+
+		idx, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+		}
+		if idx < 0 || idx > len(book.Notes)-1 {
+			return errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+		}
+		if limit < 1 {
+			return errors.Errorf("Invalid limit %d", limit)
+		}
+
+		related := core.FindRelatedNotes(dnote, bookName, idx, limit)
+
+		if log.Porcelain {
+			for _, r := range related {
+				log.Fields(r.BookName, r.Index, fmt.Sprintf("%.3f", r.Score), r.Note.Content)
+			}
+
+			return nil
+		}
+
+		if len(related) == 0 {
+			log.Plain("no related notes found\n")
+			return nil
+		}
+
+		log.Infof("notes related to %s %d\n", bookName, idx)
+		for _, r := range related {
+			log.Raw(fmt.Sprintf("  %s \033[%dm(%d)\033[0m %s%s\n", r.BookName, log.ColorYellow, r.Index, core.PriorityMarker(r.Note.Priority), core.GetTitle(r.Note.Content)))
+		}
+
+		return nil
+	}
+}
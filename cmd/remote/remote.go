@@ -0,0 +1,216 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dnote-io/cli/cmd/sync"
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * List the books on the server along with the number of notes here
+ dnote remote books
+
+ * List the notes of a book on the server
+ dnote remote notes js`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remote",
+		Short:   "Show the books and notes on the server without syncing",
+		Example: example,
+	}
+
+	cmd.AddCommand(newBooksCmd(ctx))
+	cmd.AddCommand(newNotesCmd(ctx))
+
+	return cmd
+}
+
+func newBooksCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "books",
+		Short: "List the books on the server",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := fetchServer(ctx)
+			if err != nil {
+				return err
+			}
+			if server == nil {
+				return nil
+			}
+
+			dnote, err := core.GetDnote(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read dnote")
+			}
+
+			return printBooks(dnote, server)
+		},
+	}
+
+	return cmd
+}
+
+func newNotesCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes <book name>",
+		Short: "List the notes of a book on the server",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server, err := fetchServer(ctx)
+			if err != nil {
+				return err
+			}
+			if server == nil {
+				return nil
+			}
+
+			dnote, err := core.GetDnote(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read dnote")
+			}
+
+			bookName := core.ResolveBookName(server, args[0])
+			book, ok := server[bookName]
+			if !ok {
+				return errors.Errorf("Book %s does not exist on the server", bookName)
+			}
+
+			printNotes(dnote, book)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// fetchServer returns the notes on the server, which are built from every
+// change the sync endpoint returns without uploading or applying any. It
+// returns nil if the user declines the download on a metered connection.
+func fetchServer(ctx infra.DnoteCtx) (infra.Dnote, error) {
+	apiKey, err := core.ReadAPIKey(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the API key")
+	}
+	if apiKey == "" {
+		return nil, core.NewExitError(core.ExitAuthRequired, errors.New("Login required. Please run `dnote login`"))
+	}
+
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read the config")
+	}
+
+	server, _, err := sync.FetchServerState(context.Background(), ctx, config, apiKey, 0)
+	if err != nil {
+		if err == sync.ErrDownloadDeclined {
+			log.Warnf("aborted by user\n")
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// printBooks prints the books on the server with their number of notes, and
+// the number of notes in the local book of the same name, or "-" if there is
+// none
+func printBooks(dnote, server infra.Dnote) error {
+	names := make([]string, 0, len(server))
+	for name := range server {
+		names = append(names, name)
+	}
+	core.SortBookNames(names)
+
+	if log.Porcelain {
+		for _, name := range names {
+			local := -1
+			if book, ok := dnote[core.ResolveBookName(dnote, name)]; ok {
+				local = len(book.Notes)
+			}
+
+			log.Fields(name, len(server[name].Notes), local)
+		}
+
+		return nil
+	}
+
+	if len(names) == 0 {
+		log.Plain("no books on the server\n")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "BOOK\tNOTES\tLOCAL NOTES")
+	for _, name := range names {
+		local := "-"
+		if book, ok := dnote[core.ResolveBookName(dnote, name)]; ok {
+			local = fmt.Sprintf("%d", len(book.Notes))
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\n", name, len(server[name].Notes), local)
+	}
+
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "Failed to write the table")
+	}
+
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line != "" {
+			log.Plain(line)
+		}
+	}
+
+	return nil
+}
+
+// printNotes prints the notes of the book on the server, marking the ones that
+// are not in the local book
+func printNotes(dnote infra.Dnote, book infra.Book) {
+	local := map[string]bool{}
+	for _, note := range dnote[core.ResolveBookName(dnote, book.Name)].Notes {
+		local[note.UUID] = true
+	}
+
+	log.Infof("on book %s on the server\n", book.Name)
+
+	for i, note := range book.Notes {
+		if log.Porcelain {
+			log.Fields(book.Name, i, note.UUID, local[note.UUID], note.Content)
+			continue
+		}
+
+		var mark string
+		if !local[note.UUID] {
+			mark = fmt.Sprintf(" \033[%dm(not synced here)\033[0m", log.ColorGray)
+		}
+
+		log.Raw(fmt.Sprintf("  \033[%dm(%d)\033[0m %s%s\n", log.ColorYellow, i, note.Title, mark))
+	}
+}
@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+func TestFetchServer(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+
+		addBook, err := core.NewActionAddBook("js", 1517629800)
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to make the action"))
+		}
+		addNote, err := core.NewActionAddNote("n1", "js", "closures on the server", 1517629805)
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to make the action"))
+		}
+
+		b, err := json.Marshal(map[string]interface{}{"actions": []core.Action{addBook, addNote}, "bookmark": 5})
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to marshal the response"))
+		}
+		w.Write(b)
+	}))
+	defer server.Close()
+	ctx.APIEndpoint = server.URL
+
+	edit, err := core.NewActionEditNote("n2", "js", "closures edited", 1517629810)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	local := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{{UUID: "n2", Content: "closures edited"}}},
+	}
+	if err := core.WriteConfig(ctx, infra.Config{APIKey: "test-key"}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+	if err := core.WriteDnote(ctx, local); err != nil {
+		panic(errors.Wrap(err, "Failed to write dnote"))
+	}
+	if err := core.WriteActionLog(ctx, []core.Action{edit}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the action log"))
+	}
+	if err := core.WriteTimestamp(ctx, infra.Timestamp{Bookmark: 3}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the timestamp"))
+	}
+
+	// Execute
+	got, err := fetchServer(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to fetch the server"))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+	}
+	timestamp, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+
+	testutils.AssertDeepEqual(t, paths, []string{"POST /v1/sync"}, "requests mismatch")
+	testutils.AssertEqual(t, len(got["js"].Notes), 1, "server note count mismatch")
+	testutils.AssertEqual(t, got["js"].Notes[0].UUID, "n1", "server note mismatch")
+	testutils.AssertEqual(t, got["js"].Notes[0].Title, "closures on the server", "server note title mismatch")
+	testutils.AssertDeepEqual(t, dnote, local, "the local notes should not change")
+	testutils.AssertEqual(t, len(actions), 1, "the action log should not change")
+	testutils.AssertEqual(t, timestamp.Bookmark, 3, "the bookmark should not change")
+}
+
+func TestFetchServer_LoginRequired(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	if err := core.WriteConfig(ctx, infra.Config{DisableKeychain: true}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+
+	// Execute
+	_, err := fetchServer(ctx)
+
+	// Test
+	testutils.AssertEqual(t, core.GetExitCode(err), core.ExitAuthRequired, "exit code mismatch")
+}
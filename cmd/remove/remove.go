@@ -71,6 +71,7 @@ func note(ctx infra.DnoteCtx, index int, bookName string) error {
 		return errors.Wrap(err, "Failed to get dnote")
 	}
 
+	bookName = core.ResolveBookName(dnote, bookName)
 	book, exists := dnote[bookName]
 	if !exists {
 		return errors.Errorf("Book with the name '%s' does not exist", bookName)
@@ -78,8 +79,7 @@ func note(ctx infra.DnoteCtx, index int, bookName string) error {
 	notes := book.Notes
 
 	if len(notes)-1 < index {
-		fmt.Println("Error : The note with that index is not found.")
-		return nil
+		return errors.Errorf("Book %s does not have note with index %d", bookName, index)
 	}
 
 	content := notes[index].Content
@@ -113,6 +113,13 @@ func note(ctx infra.DnoteCtx, index int, bookName string) error {
 
 // book deletes a book with the given name
 func book(ctx infra.DnoteCtx, bookName string) error {
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		return err
+	}
+
+	bookName = core.ResolveBookName(dnote, bookName)
+
 	ok, err := utils.AskConfirmation(fmt.Sprintf("delete book '%s' and all its notes?", bookName))
 	if err != nil {
 		return err
@@ -122,11 +129,6 @@ func book(ctx infra.DnoteCtx, bookName string) error {
 		return nil
 	}
 
-	dnote, err := core.GetDnote(ctx)
-	if err != nil {
-		return err
-	}
-
 	for n, book := range dnote {
 		if n == bookName {
 			delete(dnote, n)
@@ -0,0 +1,216 @@
+package replace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var bookName string
+var findText string
+var replaceText string
+var useRegex bool
+var dryRun bool
+var skipConfirm bool
+
+var example = `
+ * Preview replacing a term in the notes of a book
+ dnote replace --book js --find 'var ' --replace 'let ' --dry-run
+
+ * Replace a term in all notes
+ dnote replace --find kubectl --replace k
+
+ * Replace using a regular expression with a capture group
+ dnote replace --find 'v(\d+)\.x' --replace 'version $1' --regex`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return errors.New("Incorrect number of argument")
+	}
+	if findText == "" {
+		return errors.New("Missing the text to find. Provide it with --find")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "replace",
+		Short:   "Find and replace text across notes",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&bookName, "book", "b", "", "The book to replace in. All books are used if not given")
+	f.StringVarP(&findText, "find", "", "", "The text to find")
+	f.StringVarP(&replaceText, "replace", "", "", "The text to replace it with")
+	f.BoolVarP(&useRegex, "regex", "", false, "Treat the text to find as a regular expression, allowing $1 in the replacement")
+	f.BoolVarP(&dryRun, "dry-run", "", false, "Show the changes without applying them")
+	f.BoolVarP(&skipConfirm, "yes", "y", false, "Apply the changes without asking for confirmation")
+
+	return cmd
+}
+
+// replacement is a change to the content of a note
+type replacement struct {
+	BookName string
+	Index    int
+	Content  string
+}
+
+// newReplacer returns a function replacing every match in the content
+func newReplacer() (func(string) string, error) {
+	if !useRegex {
+		return func(s string) string {
+			return strings.Replace(s, findText, replaceText, -1)
+		}, nil
+	}
+
+	re, err := regexp.Compile(findText)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid regular expression")
+	}
+
+	return func(s string) string {
+		return re.ReplaceAllString(s, replaceText)
+	}, nil
+}
+
+func getReplacements(dnote infra.Dnote, replace func(string) string) []replacement {
+	var bookNames []string
+	for name := range dnote {
+		if bookName == "" || name == bookName {
+			bookNames = append(bookNames, name)
+		}
+	}
+	core.SortBookNames(bookNames)
+
+	var ret []replacement
+	for _, name := range bookNames {
+		for i, note := range dnote[name].Notes {
+			content := replace(note.Content)
+			if content == note.Content || strings.TrimSpace(content) == "" {
+				continue
+			}
+
+			ret = append(ret, replacement{BookName: name, Index: i, Content: content})
+		}
+	}
+
+	return ret
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		replace, err := newReplacer()
+		if err != nil {
+			return err
+		}
+
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read dnote")
+		}
+
+		if bookName != "" {
+			bookName = core.ResolveBookName(dnote, bookName)
+			if _, ok := dnote[bookName]; !ok {
+				return errors.Errorf("Book %s does not exist", bookName)
+			}
+		}
+
+		replacements := getReplacements(dnote, replace)
+		if len(replacements) == 0 {
+			log.Plain("no notes matched\n")
+			return nil
+		}
+
+		for _, r := range replacements {
+			printReplacement(dnote[r.BookName].Notes[r.Index], r)
+		}
+
+		if dryRun {
+			log.Plainf("%d notes would be changed\n", len(replacements))
+			return nil
+		}
+
+		if !skipConfirm {
+			ok, err := utils.AskConfirmation(fmt.Sprintf("apply the changes to %d notes?", len(replacements)))
+			if err != nil {
+				return errors.Wrap(err, "Failed to get confirmation")
+			}
+			if !ok {
+				log.Warnf("aborted by user\n")
+				return nil
+			}
+		}
+
+		if err := apply(ctx, dnote, replacements); err != nil {
+			return errors.Wrap(err, "Failed to apply the changes")
+		}
+
+		log.Successf("changed %d notes\n", len(replacements))
+		return nil
+	}
+}
+
+// apply edits the notes and logs the actions for all of them at once
+func apply(ctx infra.DnoteCtx, dnote infra.Dnote, replacements []replacement) error {
+	ts := time.Now().Unix()
+
+	var actions []core.Action
+	for _, r := range replacements {
+		book := dnote[r.BookName]
+
+		note := book.Notes[r.Index]
+		note.Content = r.Content
+		note.Title = core.GetTitle(r.Content)
+		note.EditedOn = ts
+		book.Notes[r.Index] = note
+
+		action, err := core.NewActionEditNote(note.UUID, book.Name, note.Content, ts)
+		if err != nil {
+			return errors.Wrap(err, "Failed to make the action")
+		}
+		actions = append(actions, action)
+	}
+
+	if err := core.LogActions(ctx, actions); err != nil {
+		return errors.Wrap(err, "Failed to log actions")
+	}
+	if err := core.WriteDnote(ctx, dnote); err != nil {
+		return errors.Wrap(err, "Failed to write dnote")
+	}
+
+	return nil
+}
+
+// printReplacement prints the lines of the note changed by the replacement
+func printReplacement(note infra.Note, r replacement) {
+	if log.Porcelain {
+		log.Fields(r.BookName, r.Index, r.Content)
+		return
+	}
+
+	log.Printf("%s \033[%dm(%d)\033[0m %s\n", r.BookName, log.ColorYellow, r.Index, note.Title)
+
+	for _, l := range core.DiffLines(note.Content, r.Content) {
+		switch l.Op {
+		case core.DiffDelete:
+			log.Raw(fmt.Sprintf("    \033[%dm- %s\033[0m\n", log.ColorRed, l.Text))
+		case core.DiffInsert:
+			log.Raw(fmt.Sprintf("    \033[%dm+ %s\033[0m\n", log.ColorGreen, l.Text))
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package root
 
 import (
+	"path/filepath"
+
 	"github.com/dnote-io/cli/core"
 	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
 	"github.com/dnote-io/cli/migrate"
 	"github.com/dnote-io/cli/upgrade"
 	"github.com/pkg/errors"
@@ -10,10 +13,87 @@ import (
 )
 
 var root = &cobra.Command{
-	Use:           "dnote",
-	Short:         "Dnote - Instantly capture what you learn while coding",
-	SilenceErrors: true,
-	SilenceUsage:  true,
+	Use:               "dnote",
+	Short:             "Dnote - Instantly capture what you learn while coding",
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	PersistentPreRunE: persistentPreRun,
+	// Run the root command, rather than only printing the usage, so that the
+	// local data is migrated when dnote is run without a command
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var verbosity string
+var logFile string
+
+// defaultLogFile is the value of the log-file flag given without a path. It
+// is replaced with the path of the log file in the dnote directory.
+var defaultLogFile = "default"
+
+// logFilePath is the path of the log file in the dnote directory
+var logFilePath string
+
+// dnoteCtx is the context given to Prepare, used to migrate the local data
+// before a command runs
+var dnoteCtx infra.DnoteCtx
+
+func init() {
+	f := root.PersistentFlags()
+	f.BoolVarP(&log.Quiet, "quiet", "q", false, "Suppress the progress and success messages")
+	f.BoolVarP(&log.Porcelain, "porcelain", "", false, "Print results in a stable format for scripts")
+	f.StringVarP(&verbosity, "verbosity", "", "", "The least severe level to log: error, warn, info, or debug")
+	f.StringVarP(&logFile, "log-file", "", "", "Write the log to the file, or to logs/dnote.log in the dnote directory if no path is given")
+	f.Lookup("log-file").NoOptDefVal = defaultLogFile
+}
+
+func persistentPreRun(cmd *cobra.Command, args []string) error {
+	if verbosity != "" {
+		if err := log.SetVerbosity(verbosity); err != nil {
+			return err
+		}
+	}
+
+	if logFile != "" {
+		path := logFile
+		if path == defaultLogFile {
+			path = logFilePath
+		}
+
+		if err := log.OpenFile(path); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("running %s with %v", cmd.CommandPath(), args)
+
+	if !shouldMigrate(cmd) {
+		return nil
+	}
+
+	if err := migrate.Migrate(dnoteCtx); err != nil {
+		return errors.Wrap(err, "Failed to perform migration")
+	}
+	if err := core.MigrateAPIKey(dnoteCtx); err != nil {
+		return errors.Wrap(err, "Failed to move the API key to the keychain")
+	}
+	if err := upgrade.AutoUpgrade(dnoteCtx); err != nil {
+		return errors.Wrap(err, "Failed to auto upgrade")
+	}
+
+	return nil
+}
+
+// shouldMigrate checks if the local data should be migrated before the
+// command runs. `sync --show-migrations` reports the pending migrations, so
+// it must run before them, and must work even if one of them fails.
+func shouldMigrate(cmd *cobra.Command) bool {
+	if f := cmd.Flags().Lookup("show-migrations"); f != nil && f.Changed {
+		return false
+	}
+
+	return true
 }
 
 // Register adds a new command
@@ -21,13 +101,22 @@ func Register(cmd *cobra.Command) {
 	root.AddCommand(cmd)
 }
 
+// SetHelpCommand replaces the default help command
+func SetHelpCommand(cmd *cobra.Command) {
+	root.SetHelpCommand(cmd)
+}
+
 // Execute runs the main command
 func Execute() error {
 	return root.Execute()
 }
 
-// Prepare initializes necessary files
+// Prepare initializes necessary files. The local data is migrated later, right
+// before the command runs.
 func Prepare(ctx infra.DnoteCtx) error {
+	dnoteCtx = ctx
+	logFilePath = filepath.Join(ctx.DnoteDir, "logs", "dnote.log")
+
 	err := core.MigrateToDnoteDir(ctx)
 	if err != nil {
 		return errors.Wrap(err, "Failed to initialize dnote dir")
@@ -63,15 +152,5 @@ func Prepare(ctx infra.DnoteCtx) error {
 		return errors.Wrap(err, "Failed to create migration file")
 	}
 
-	err = migrate.Migrate(ctx)
-	if err != nil {
-		return errors.Wrap(err, "Failed to perform migration")
-	}
-
-	err = upgrade.AutoUpgrade(ctx)
-	if err != nil {
-		return errors.Wrap(err, "Failed to auto upgrade")
-	}
-
 	return nil
 }
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"io/ioutil"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * List the rules in dnoterc
+ dnote rules
+
+ * Show the book a note in the file would be added to
+ dnote rules test note.txt`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rules",
+		Short:   "List the rules choosing the book of new notes",
+		Example: example,
+		RunE:    newRun(ctx),
+	}
+
+	cmd.AddCommand(newTestCmd(ctx))
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		config, err := core.ReadConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the config")
+		}
+
+		if len(config.Rules) == 0 {
+			log.Plain("no rules in dnoterc\n")
+			return nil
+		}
+
+		for i, rule := range config.Rules {
+			if log.Porcelain {
+				log.Fields(i+1, rule.Pattern, rule.Book)
+				continue
+			}
+
+			log.Printf("\033[%dm(%d)\033[0m %s → %s\n", log.ColorYellow, i+1, rule.Pattern, rule.Book)
+		}
+
+		return nil
+	}
+}
+
+func newTestCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <path>",
+		Short: "Show the rule matching the content of the file",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "Failed to read %s", path)
+			}
+
+			config, err := core.ReadConfig(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to read the config")
+			}
+
+			content := core.SanitizeContent(string(b))
+			rule, ok, err := core.MatchRule(config.Rules, content)
+			if err != nil {
+				return errors.Wrap(err, "Failed to apply the rules")
+			}
+
+			if !ok {
+				if log.Porcelain {
+					log.Fields("")
+					return nil
+				}
+
+				log.Plain("no rule matches\n")
+				return nil
+			}
+
+			if log.Porcelain {
+				log.Fields(rule.Book, rule.Pattern)
+				return nil
+			}
+
+			log.Successf("would be added to %s by %s\n", rule.Book, rule.Pattern)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,121 @@
+package snapshots
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * List the snapshots taken before each sync
+ dnote snapshots list
+
+ * Restore the local data to the state before the last sync
+ dnote snapshots rollback 1`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "snapshots",
+		Short:   "Manage the snapshots of the local data taken before each sync",
+		Example: example,
+	}
+
+	cmd.AddCommand(newListCmd(ctx))
+	cmd.AddCommand(newRollbackCmd(ctx))
+
+	return cmd
+}
+
+func newListCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the snapshots, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshots, err := core.ListSnapshots(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to list the snapshots")
+			}
+
+			if len(snapshots) == 0 {
+				log.Plain("no snapshots. one is taken before each sync\n")
+				return nil
+			}
+
+			for i, s := range snapshots {
+				if log.Porcelain {
+					log.Fields(i+1, s.CreatedAt.Unix(), s.Size)
+					continue
+				}
+
+				log.Printf("\033[%dm(%d)\033[0m %s %s\n", log.ColorYellow, i+1, s.CreatedAt.Format("2006-01-02 15:04:05"), utils.FormatSize(s.Size))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newRollbackCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback <number>",
+		Short: "Restore the local data from a snapshot",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return errors.Wrapf(err, "Failed to parse the given number %+v", args[0])
+			}
+
+			snapshots, err := core.ListSnapshots(ctx)
+			if err != nil {
+				return errors.Wrap(err, "Failed to list the snapshots")
+			}
+			if n < 1 || n > len(snapshots) {
+				return errors.Errorf("Snapshot %d does not exist. Run `dnote snapshots list` to see the snapshots", n)
+			}
+			snapshot := snapshots[n-1]
+
+			uploaded, err := core.HasUploadedChanges(ctx, snapshot)
+			if err != nil {
+				return errors.Wrap(err, "Failed to check the snapshot")
+			}
+			if uploaded {
+				return errors.Errorf("The local changes in snapshot %d have been synced since it was taken. Restoring it would sync them again", n)
+			}
+
+			question := fmt.Sprintf("restore the local data to the state at %s? changes made since then will be lost", snapshot.CreatedAt.Format("2006-01-02 15:04:05"))
+			ok, err := utils.AskConfirmation(question)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get confirmation")
+			}
+			if !ok {
+				log.Warnf("aborted by user\n")
+				return nil
+			}
+
+			if err := core.RestoreSnapshot(ctx, snapshot); err != nil {
+				return errors.Wrap(err, "Failed to restore the snapshot")
+			}
+
+			log.Success("restored the snapshot\n")
+			return nil
+		},
+	}
+
+	return cmd
+}
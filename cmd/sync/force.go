@@ -0,0 +1,237 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// ErrDownloadDeclined is returned when the user declines to download the
+// state of the server on a metered connection
+var ErrDownloadDeclined = errors.New("The download was declined")
+
+// confirmForceSync asks the user to type the name of the flag to go ahead with
+// a forced sync, since it discards either the local changes or the changes on
+// the server
+func confirmForceSync() (bool, error) {
+	warning := "this overwrites the notes on the server with the notes on this machine. changes on the server that are not here will be lost"
+	word := "push-force"
+	if pullForce {
+		warning = "this overwrites the notes on this machine with the notes on the server. local changes that have not been synced will be lost"
+		word = "pull-force"
+	}
+
+	log.Warnf("%s\n", warning)
+	log.Askf("type %s to continue: ", word)
+
+	res, err := utils.GetInput()
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to get user input")
+	}
+
+	return strings.TrimSpace(res) == word, nil
+}
+
+// FetchServerState downloads every change made on the server from the start,
+// and returns the notes they add up to along with the bookmark of the last one.
+// The changes are applied in a temporary directory, leaving the local data as is.
+func FetchServerState(c context.Context, ctx infra.DnoteCtx, config infra.Config, apiKey string, rate int64) (infra.Dnote, int, error) {
+	payload, err := getPayload([]core.Action{}, infra.Timestamp{})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to get dnote payload")
+	}
+
+	log.Infof("downloading the state of the server.")
+	resp, err := postActions(c, ctx, config, apiKey, payload, rate)
+	if err != nil {
+		log.Raw("\n")
+		if err := getAbortError(c); err != nil {
+			return nil, 0, err
+		}
+
+		return nil, 0, core.NewExitError(core.ExitServerError, errors.Wrap(err, "Failed to post to the server"))
+	}
+	defer resp.Body.Close()
+	log.Debugf("sync responded with %s, request id: %s", resp.Status, core.GetRequestID(resp))
+
+	if _, err := core.CheckServerVersion(resp); err != nil {
+		log.Raw("\n")
+		return nil, 0, core.NewExitError(core.ExitServerError, err)
+	}
+
+	// Nothing was uploaded, so declining leaves the server as it is
+	if resp.StatusCode == http.StatusOK && config.Sync.Metered {
+		log.Raw("\n")
+		ok, err := confirmDownload(resp.ContentLength, config.Sync)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "Failed to confirm the download")
+		}
+		if !ok {
+			return nil, 0, ErrDownloadDeclined
+		}
+	}
+
+	body, err := ioutil.ReadAll(newThrottledReader(resp.Body, rate))
+	if err != nil {
+		log.Raw("\n")
+		if err := getAbortError(c); err != nil {
+			return nil, 0, err
+		}
+
+		return nil, 0, errors.Wrap(err, "Failed to read failed response body")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Raw("\n")
+		return nil, 0, getStatusError(resp.StatusCode, body)
+	}
+	log.Raw(" done.\n")
+
+	var respData responseData
+	if err := json.Unmarshal(body, &respData); err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to unmarshal payload")
+	}
+
+	dir, err := ioutil.TempDir("", "dnote-server")
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to create a temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	tmpCtx := infra.DnoteCtx{DnoteDir: dir}
+	if err := core.InitDnoteFile(tmpCtx); err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to create dnote file")
+	}
+
+	log.Infof("resolving delta (total %d).", len(respData.Actions))
+	if err := core.ReduceAll(tmpCtx, respData.Actions); err != nil {
+		log.Raw("\n")
+		return nil, 0, errors.Wrap(err, "Failed to reduce returned actions")
+	}
+	log.Raw(" done.\n")
+
+	dnote, err := core.GetDnote(tmpCtx)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to read the state of the server")
+	}
+
+	return dnote, respData.Bookmark, nil
+}
+
+// runPullForce replaces the local notes with the state of the server, and
+// discards the local changes that have not been synced. The changes it makes
+// to the local notes are recorded as the last sync.
+func runPullForce(c context.Context, ctx infra.DnoteCtx, config infra.Config, apiKey string, rate int64) error {
+	server, bookmark, err := FetchServerState(c, ctx, config, apiKey, rate)
+	if err != nil {
+		return err
+	}
+
+	local, err := core.GetDnote(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read dnote")
+	}
+
+	now := time.Now().Unix()
+	changes, err := core.GetChangeActions(local, server, now)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get the changes from the server")
+	}
+	record, err := core.NewSyncRecord(ctx, changes, now)
+	if err != nil {
+		return errors.Wrap(err, "Failed to record the sync")
+	}
+
+	// Whether a note was read, its priority, and the note it was copied from
+	// are only known locally
+	kept := map[string]infra.Note{}
+	for _, book := range local {
+		for _, note := range book.Notes {
+			kept[note.UUID] = note
+		}
+	}
+	for _, book := range server {
+		for i, note := range book.Notes {
+			book.Notes[i].ReadOn = kept[note.UUID].ReadOn
+			book.Notes[i].Priority = kept[note.UUID].Priority
+			book.Notes[i].CopiedFrom = kept[note.UUID].CopiedFrom
+		}
+	}
+
+	if err := core.WriteDnote(ctx, server); err != nil {
+		return errors.Wrap(err, "Failed to write dnote")
+	}
+	if err := core.ClearActionLog(ctx); err != nil {
+		return errors.Wrap(err, "Failed to clear the action log")
+	}
+	if err := core.ClearPendingActions(ctx); err != nil {
+		return errors.Wrap(err, "Failed to clear the changes from the server")
+	}
+	if err := core.WriteLastSync(ctx, record); err != nil {
+		return errors.Wrap(err, "Failed to write the last sync")
+	}
+	if err := writeBookmark(ctx, bookmark); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// preparePushForce replaces the action log with the actions that turn the
+// current state of the server into the local notes, and moves the bookmark to
+// that state, so that the sync that follows overwrites the server
+func preparePushForce(c context.Context, ctx infra.DnoteCtx, config infra.Config, apiKey string, rate int64) error {
+	server, bookmark, err := FetchServerState(c, ctx, config, apiKey, rate)
+	if err != nil {
+		return err
+	}
+
+	local, err := core.GetDnote(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read dnote")
+	}
+
+	updated, actions, err := core.GetOverwriteActions(server, local, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "Failed to get the changes to overwrite the server")
+	}
+
+	if err := core.WriteDnote(ctx, updated); err != nil {
+		return errors.Wrap(err, "Failed to write dnote")
+	}
+	if err := core.WriteActionLog(ctx, actions); err != nil {
+		return errors.Wrap(err, "Failed to write the action log")
+	}
+	if err := core.ClearPendingActions(ctx); err != nil {
+		return errors.Wrap(err, "Failed to clear the changes from the server")
+	}
+	if err := writeBookmark(ctx, bookmark); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func writeBookmark(ctx infra.DnoteCtx, bookmark int) error {
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the timestamp")
+	}
+
+	ts.Bookmark = bookmark
+	if err := core.WriteTimestamp(ctx, ts); err != nil {
+		return errors.Wrap(err, "Failed to update bookmark")
+	}
+
+	return nil
+}
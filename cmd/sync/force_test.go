@@ -0,0 +1,192 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+// newServer returns a server responding to every sync with the actions adding
+// the js book with a note n1, and the bookmark 5. The bookmarks and the counts
+// of the actions in the requests are appended to the given slices.
+func newServer(bookmarks *[]int, uploaded *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bookmark, actions := readPayload(r)
+		*bookmarks = append(*bookmarks, bookmark)
+		*uploaded = append(*uploaded, len(actions))
+
+		addBook, err := core.NewActionAddBook("js", 1517629800)
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to make the action"))
+		}
+		addNote, err := core.NewActionAddNote("n1", "js", "closures on the server", 1517629805)
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to make the action"))
+		}
+
+		writeResponse(w, []core.Action{addBook, addNote}, 5)
+	}))
+}
+
+// setupForceSync writes the local data, which has the js book with the note n1
+// that was read and edited locally, and the go book only found locally
+func setupForceSync(ctx infra.DnoteCtx, config infra.Config) {
+	edit, err := core.NewActionEditNote("n1", "js", "closures edited", 1517629810)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	setupSync(ctx, config, []core.Action{edit}, 3)
+
+	dnote := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "closures edited", ReadOn: 1517629900, Priority: core.PriorityHigh, CopiedFrom: "n0"},
+		}},
+		"go": infra.Book{Name: "go", Notes: []infra.Note{
+			{UUID: "n2", Content: "goroutines"},
+		}},
+	}
+	if err := core.WriteDnote(ctx, dnote); err != nil {
+		panic(errors.Wrap(err, "Failed to write dnote"))
+	}
+}
+
+func TestRunPullForce(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	var bookmarks, uploaded []int
+	server := newServer(&bookmarks, &uploaded)
+	defer server.Close()
+	ctx.APIEndpoint = server.URL
+
+	setupForceSync(ctx, infra.Config{})
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the config"))
+	}
+
+	// Execute
+	if err := runPullForce(context.Background(), ctx, config, config.APIKey, 0); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to pull the state of the server"))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+	}
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+	record, err := core.ReadLastSync(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the last sync"))
+	}
+
+	testutils.AssertEqual(t, bookmarks[0], 0, "the whole state of the server should be requested")
+	testutils.AssertEqual(t, uploaded[0], 0, "nothing should be uploaded")
+	testutils.AssertEqual(t, len(dnote), 1, "book count mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Content, "closures on the server", "note content mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].ReadOn, int64(1517629900), "whether the note was read should be kept")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Priority, core.PriorityHigh, "the priority of the note should be kept")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].CopiedFrom, "n0", "the note it was copied from should be kept")
+	testutils.AssertEqual(t, len(actions), 0, "the local changes should be discarded")
+	testutils.AssertEqual(t, ts.Bookmark, 5, "bookmark mismatch")
+	testutils.AssertEqual(t, len(record.Actions), 2, "the changes should be recorded as the last sync")
+	testutils.AssertEqual(t, record.Actions[0].Type, core.ActionRemoveBook, "recorded action type mismatch")
+	testutils.AssertEqual(t, record.Actions[1].Type, core.ActionEditNote, "recorded action type mismatch")
+	testutils.AssertEqual(t, record.Previous["n1"], "closures edited", "the content before the sync should be recorded")
+}
+
+func TestPreparePushForce(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	var bookmarks, uploaded []int
+	server := newServer(&bookmarks, &uploaded)
+	defer server.Close()
+	ctx.APIEndpoint = server.URL
+
+	setupForceSync(ctx, infra.Config{})
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the config"))
+	}
+
+	// Execute
+	if err := preparePushForce(context.Background(), ctx, config, config.APIKey, 0); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to prepare the forced push"))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+	}
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+
+	testutils.AssertEqual(t, uploaded[0], 0, "nothing should be uploaded")
+	testutils.AssertEqual(t, len(dnote), 2, "book count mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].UUID, "n1", "note on the server should keep its uuid")
+	testutils.AssertNotEqual(t, dnote["go"].Notes[0].UUID, "n2", "note not on the server should get a new uuid")
+	testutils.AssertEqual(t, len(actions), 3, "action log length mismatch")
+	testutils.AssertEqual(t, actions[0].Type, core.ActionAddBook, "action type mismatch")
+	testutils.AssertEqual(t, actions[1].Type, core.ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, actions[2].Type, core.ActionEditNote, "action type mismatch")
+	testutils.AssertEqual(t, ts.Bookmark, 5, "bookmark mismatch")
+}
+
+func TestFetchServerState_MeteredDecline(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	var bookmarks, uploaded []int
+	server := newServer(&bookmarks, &uploaded)
+	defer server.Close()
+	ctx.APIEndpoint = server.URL
+
+	setupForceSync(ctx, infra.Config{Sync: infra.SyncConfig{Metered: true, MeteredLimit: 10}})
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the config"))
+	}
+
+	restoreStdin := setStdin("n\n")
+	defer restoreStdin()
+
+	// Execute
+	err = runPullForce(context.Background(), ctx, config, config.APIKey, 0)
+
+	// Test
+	dnote, dnoteErr := core.GetDnote(ctx)
+	if dnoteErr != nil {
+		t.Fatal(errors.Wrap(dnoteErr, "Failed to get dnote"))
+	}
+
+	testutils.AssertEqual(t, err, ErrDownloadDeclined, "error mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Content, "closures edited", "the local notes should be kept")
+}
@@ -3,20 +3,54 @@ package sync
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dnote-io/cli/core"
 	"github.com/dnote-io/cli/infra"
 	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/migrate"
+	"github.com/dnote-io/cli/utils"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var showMigrations bool
+var maxBandwidth string
+var timeout time.Duration
+var pushForce bool
+var pullForce bool
+
+// defaultMeteredLimit is the size in bytes of the largest download made
+// without a confirmation on a metered connection, unless set in the config
+var defaultMeteredLimit int64 = 1024 * 1024
+
 var example = `
-  dnote sync`
+  dnote sync
+
+  * Show the local schema migrations and whether they have been run
+  dnote sync --show-migrations
+
+  * Limit the transfer speed to 256 kilobytes per second
+  dnote sync --max-bandwidth 256KB
+
+  * Give up if the sync takes longer than 2 minutes
+  dnote sync --timeout 2m
+
+  * Make the server the same as this machine, discarding the other changes
+  dnote sync --push-force
+
+  * Make this machine the same as the server, discarding the local changes
+  dnote sync --pull-force`
 
 func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
 	cmd := &cobra.Command{
@@ -27,9 +61,39 @@ func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
 		RunE:    newRun(ctx),
 	}
 
+	f := cmd.Flags()
+	f.BoolVarP(&showMigrations, "show-migrations", "", false, "Print the local schema migrations instead of syncing")
+	f.StringVarP(&maxBandwidth, "max-bandwidth", "", "", "The maximum transfer speed per second, such as 512KB or 1MB")
+	f.DurationVarP(&timeout, "timeout", "", 0, "The time the sync can take before it is aborted, such as 2m")
+	f.BoolVarP(&pushForce, "push-force", "", false, "Overwrite the server with the local notes")
+	f.BoolVarP(&pullForce, "pull-force", "", false, "Overwrite the local notes with the server, discarding the local changes")
+
 	return cmd
 }
 
+func printMigrations(ctx infra.DnoteCtx) error {
+	infos, err := migrate.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get the migration status")
+	}
+
+	for _, info := range infos {
+		status := "done"
+		if !info.Done {
+			status = "pending"
+		}
+
+		if log.Porcelain {
+			log.Fields(info.ID, info.Description, status)
+			continue
+		}
+
+		log.Plainf("#%d %s \033[%dm(%s)\033[0m\n", info.ID, info.Description, log.ColorYellow, status)
+	}
+
+	return nil
+}
+
 type responseData struct {
 	Actions  []core.Action `json:"actions"`
 	Bookmark int           `json:"bookmark"`
@@ -42,6 +106,22 @@ type syncPayload struct {
 
 func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 	return func(cmd *cobra.Command, args []string) error {
+		if showMigrations {
+			return printMigrations(ctx)
+		}
+		if pushForce && pullForce {
+			return errors.New("Only one of --push-force and --pull-force can be given")
+		}
+
+		rate, err := parseBandwidth(maxBandwidth)
+		if err != nil {
+			return err
+		}
+
+		apiKey, err := core.ReadAPIKey(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the API key")
+		}
 		config, err := core.ReadConfig(ctx)
 		if err != nil {
 			return errors.Wrap(err, "Failed to read the config")
@@ -55,35 +135,133 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return errors.Wrap(err, "Failed to read the action log")
 		}
 
-		if config.APIKey == "" {
-			fmt.Println("Login required. Please run `dnote login`")
+		if apiKey == "" {
+			return core.NewExitError(core.ExitAuthRequired, errors.New("Login required. Please run `dnote login`"))
+		}
+
+		if pushForce || pullForce {
+			ok, err := confirmForceSync()
+			if err != nil {
+				return errors.Wrap(err, "Failed to get confirmation")
+			}
+			if !ok {
+				log.Warnf("aborted by user\n")
+				return nil
+			}
+		}
+
+		snapshot, err := core.TakeSnapshot(ctx, config.Snapshots)
+		if err != nil {
+			return errors.Wrap(err, "Failed to take a snapshot of the local data")
+		}
+
+		syncCtx, cancel := newSyncContext(config)
+		defer cancel()
+
+		if pullForce {
+			if err := runPullForce(syncCtx, ctx, config, apiKey, rate); err != nil {
+				if err == ErrDownloadDeclined {
+					log.Warnf("aborted by user\n")
+					return nil
+				}
+
+				return errors.Wrap(err, "Failed to pull the state of the server")
+			}
+
+			log.Success("success. the local notes are the same as the server\n")
 			return nil
 		}
 
+		if pushForce {
+			if err := preparePushForce(syncCtx, ctx, config, apiKey, rate); err != nil {
+				if err == ErrDownloadDeclined {
+					log.Warnf("aborted by user\n")
+					return nil
+				}
+
+				return errors.Wrap(err, "Failed to prepare the changes to overwrite the server")
+			}
+
+			if actions, err = core.ReadActionLog(ctx); err != nil {
+				return errors.Wrap(err, "Failed to read the action log")
+			}
+			if timestamp, err = core.ReadTimestamp(ctx); err != nil {
+				return errors.Wrap(err, "Failed to read the timestamp")
+			}
+		} else if err := applyPendingActions(syncCtx, ctx); err != nil {
+			return err
+		}
+
+		// The forced push has just downloaded the state of the server
+		if config.Sync.Metered && !pushForce {
+			ok, err := confirmMeteredSync(syncCtx, ctx, config, apiKey, timestamp, rate)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				log.Infof("nothing was synced. run `dnote sync` again to sync the changes\n")
+				return nil
+			}
+		}
+
 		payload, err := getPayload(actions, timestamp)
 		if err != nil {
 			return errors.Wrap(err, "Failed to get dnote payload")
 		}
+		log.Debugf("posting %d actions after the bookmark %d", len(actions), timestamp.Bookmark)
 
 		log.Infof("writing changes (total %d).", len(actions))
-		resp, err := postActions(ctx, config.APIKey, payload)
+		reqCtx, detach := newRequestContext(syncCtx)
+		requestedAt := time.Now()
+		resp, err := postActions(reqCtx, ctx, config, apiKey, payload, rate)
 		if err != nil {
-			return errors.Wrap(err, "Failed to post to the server ")
+			log.Raw("\n")
+			if err := getAbortError(syncCtx); err != nil {
+				return err
+			}
+
+			return core.NewExitError(core.ExitServerError, errors.Wrap(err, "Failed to post to the server"))
 		}
+		defer resp.Body.Close()
+		skew, hasSkew := core.GetClockSkew(resp, requestedAt, time.Now())
+		log.Debugf("sync responded with %s in %s, request id: %s", resp.Status, time.Since(requestedAt), core.GetRequestID(resp))
 
-		body, err := ioutil.ReadAll(resp.Body)
+		// Refuse to apply the response of a server that does not support this
+		// version, since its changes may not be in a format the CLI understands
+		newerVersion, err := core.CheckServerVersion(resp)
+		if err != nil {
+			log.Raw("\n")
+			return core.NewExitError(core.ExitServerError, err)
+		}
+
+		// The server has accepted the local changes. The response must be read
+		// to the end to learn the new bookmark, even if the sync is aborted.
+		if resp.StatusCode == http.StatusOK {
+			detach()
+
+			if err := core.MarkSnapshotUploaded(ctx, snapshot); err != nil {
+				return errors.Wrap(err, "Failed to mark the snapshot")
+			}
+		}
+
+		body, err := ioutil.ReadAll(newThrottledReader(resp.Body, rate))
 		if err != nil {
+			log.Raw("\n")
+			if resp.StatusCode != http.StatusOK {
+				if err := getAbortError(syncCtx); err != nil {
+					return err
+				}
+			}
+
 			return errors.Wrap(err, "Failed to read failed response body")
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			bodyStr := string(body)
-
-			fmt.Println("")
-			return errors.Errorf("Server error: %s", bodyStr)
+			log.Raw("\n")
+			return getStatusError(resp.StatusCode, body)
 		}
 
-		fmt.Println(" done.")
+		log.Raw(" done.\n")
 
 		var respData responseData
 		err = json.Unmarshal(body, &respData)
@@ -91,25 +269,16 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 			return errors.Wrap(err, "Failed to unmarshal payload")
 		}
 
-		log.Infof("resolving delta (total %d).", len(respData.Actions))
-		err = core.ReduceAll(ctx, respData.Actions)
-		if err != nil {
-			return errors.Wrap(err, "Failed to reduce returned actions")
-		}
-		fmt.Println(" done.")
-
-		// Update bookmark
-		ts, err := core.ReadTimestamp(ctx)
-		ts.Bookmark = respData.Bookmark
-
-		err = core.WriteTimestamp(ctx, ts)
-		if err != nil {
-			return errors.Wrap(err, "Failed to update bookmark")
+		if err := handleResponse(syncCtx, ctx, respData, skew, hasSkew); err != nil {
+			return err
 		}
 
 		log.Success("success\n")
-		if err := core.ClearActionLog(ctx); err != nil {
-			return errors.Wrap(err, "Failed to clear the action log")
+		if newerVersion {
+			log.Infof("a newer version of dnote is available. run `dnote upgrade` to upgrade\n")
+		}
+		if hasSkew && core.IsClockSkewed(skew) {
+			log.Warnf("the clock of this machine is off by %s from the server. please check the system time\n", time.Duration(skew)*time.Second)
 		}
 
 		return nil
@@ -117,7 +286,16 @@ func newRun(ctx infra.DnoteCtx) core.RunEFunc {
 }
 
 func getPayload(actions []core.Action, timestamp infra.Timestamp) (*bytes.Buffer, error) {
-	compressedActions, err := compressActions(actions)
+	// Send timestamps relative to the server clock so that actions from machines
+	// with wrong clocks are ordered correctly. A copy is shifted so that the
+	// actions of the caller keep their local timestamps.
+	shifted := make([]core.Action, len(actions))
+	copy(shifted, actions)
+	for idx := range shifted {
+		shifted[idx].Timestamp += timestamp.ClockSkew
+	}
+
+	compressedActions, err := compressActions(shifted)
 	if err != nil {
 		return &bytes.Buffer{}, errors.Wrap(err, "Failed to compress actions")
 	}
@@ -157,17 +335,233 @@ func compressActions(actions []core.Action) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func postActions(ctx infra.DnoteCtx, APIKey string, payload *bytes.Buffer) (*http.Response, error) {
+// newSyncContext returns a context that is canceled when the user interrupts
+// the sync or the sync timeout passes
+func newSyncContext(config infra.Config) (context.Context, context.CancelFunc) {
+	d := timeout
+	if d == 0 && config.Sync.Timeout > 0 {
+		d = time.Duration(config.Sync.Timeout) * time.Second
+	}
+
+	var c context.Context
+	var cancel context.CancelFunc
+	if d > 0 {
+		c, cancel = context.WithTimeout(context.Background(), d)
+	} else {
+		c, cancel = context.WithCancel(context.Background())
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-c.Done():
+		}
+
+		signal.Stop(interrupts)
+	}()
+
+	return c, cancel
+}
+
+// getAbortError returns the error to report if the sync was interrupted or
+// timed out, or nil if it was not aborted
+func getAbortError(c context.Context) error {
+	switch c.Err() {
+	case context.Canceled:
+		return errors.New("Sync was interrupted")
+	case context.DeadlineExceeded:
+		return core.NewExitError(core.ExitServerError, errors.New("Sync timed out"))
+	}
+
+	return nil
+}
+
+// newRequestContext returns a context for the sync request, which is canceled
+// along with c until the returned function is called. It is called once the
+// server has accepted the local changes, so that aborting the sync no longer
+// stops the response from being read.
+func newRequestContext(c context.Context) (context.Context, func()) {
+	ret, cancel := context.WithCancel(context.Background())
+	detached := make(chan struct{})
+
+	go func() {
+		select {
+		case <-c.Done():
+			select {
+			case <-detached:
+			default:
+				cancel()
+			}
+		case <-detached:
+		}
+	}()
+
+	return ret, func() { close(detached) }
+}
+
+// handleResponse moves the bookmark past the changes in the response of a sync
+// accepted by the server, and applies them. The uploaded changes are removed
+// from the action log first, so that if the sync is aborted or fails to apply
+// the changes, they are not uploaded again, and the changes from the server
+// are applied by the next sync.
+func handleResponse(c context.Context, ctx infra.DnoteCtx, respData responseData, skew int64, hasSkew bool) error {
+	if err := core.WritePendingActions(ctx, respData.Actions); err != nil {
+		return errors.Wrap(err, "Failed to save the changes from the server")
+	}
+
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the timestamp")
+	}
+	ts.Bookmark = respData.Bookmark
+	if hasSkew {
+		ts.ClockSkew = skew
+	}
+	if err := core.WriteTimestamp(ctx, ts); err != nil {
+		return errors.Wrap(err, "Failed to update bookmark")
+	}
+
+	if err := core.ClearActionLog(ctx); err != nil {
+		return errors.Wrap(err, "Failed to clear the action log")
+	}
+
+	if err := getAbortError(c); err != nil {
+		log.Warnf("the local changes were uploaded. the changes from the server will be applied on the next sync\n")
+		return err
+	}
+
+	return applyPendingActions(c, ctx)
+}
+
+// applyPendingActions applies the changes downloaded from the server that have
+// not been applied yet, and records them as the last sync. If it fails or the
+// sync is aborted, the local data is left unchanged and the changes are kept
+// for the next sync.
+func applyPendingActions(c context.Context, ctx infra.DnoteCtx) error {
+	if !utils.FileExists(core.GetPendingPath(ctx)) {
+		return nil
+	}
+
+	actions, err := core.ReadPendingActions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the changes from the server")
+	}
+
+	record, err := core.NewSyncRecord(ctx, actions, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "Failed to record the sync")
+	}
+
+	state, err := saveLocalState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to save the local state")
+	}
+
+	log.Infof("resolving delta (total %d).", len(actions))
+	if err := reduceActions(c, ctx, actions, record); err != nil {
+		if restoreErr := state.restore(); restoreErr != nil {
+			return errors.Wrap(restoreErr, "Failed to restore the local state")
+		}
+
+		log.Raw("\n")
+		if err := getAbortError(c); err != nil {
+			log.Warnf("the changes from the server will be applied on the next sync\n")
+			return err
+		}
+
+		return errors.Wrap(err, "Failed to apply the changes from the server. Your local data was left unchanged")
+	}
+	log.Raw(" done.\n")
+
+	return nil
+}
+
+// reduceActions reduces the actions from the server, stopping early if the
+// sync is aborted, and records them as the last sync
+func reduceActions(c context.Context, ctx infra.DnoteCtx, actions []core.Action, record core.SyncRecord) error {
+	for _, action := range actions {
+		if err := c.Err(); err != nil {
+			return err
+		}
+
+		if err := core.Reduce(ctx, action); err != nil {
+			return errors.Wrap(err, "Failed to reduce returned actions")
+		}
+	}
+
+	if err := core.WriteLastSync(ctx, record); err != nil {
+		return errors.Wrap(err, "Failed to write the last sync")
+	}
+	if err := core.ClearPendingActions(ctx); err != nil {
+		return errors.Wrap(err, "Failed to clear the changes from the server")
+	}
+
+	return nil
+}
+
+// localState is the content of the local files changed by applying the
+// actions from the server, keyed by path
+type localState map[string][]byte
+
+// saveLocalState reads the local files changed by applying the actions from
+// the server so that they can be restored if it fails
+func saveLocalState(ctx infra.DnoteCtx) (localState, error) {
+	ret := localState{}
+
+	paths := []string{core.GetDnotePath(ctx), core.GetLastSyncPath(ctx)}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			ret[path] = nil
+			continue
+		}
+		if err != nil {
+			return ret, errors.Wrapf(err, "Failed to read %s", path)
+		}
+
+		ret[path] = b
+	}
+
+	return ret, nil
+}
+
+// restore writes back the saved files, and removes the ones that did not exist
+func (s localState) restore() error {
+	for path, b := range s {
+		if b == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "Failed to remove %s", path)
+			}
+
+			continue
+		}
+
+		if err := ioutil.WriteFile(path, b, 0644); err != nil {
+			return errors.Wrapf(err, "Failed to restore %s", path)
+		}
+	}
+
+	return nil
+}
+
+func postActions(c context.Context, ctx infra.DnoteCtx, config infra.Config, APIKey string, payload *bytes.Buffer, rate int64) (*http.Response, error) {
 	endpoint := fmt.Sprintf("%s/v1/sync", ctx.APIEndpoint)
-	req, err := http.NewRequest("POST", endpoint, payload)
+	size := int64(payload.Len())
+	req, err := http.NewRequest("POST", endpoint, newThrottledReader(payload, rate))
 	if err != nil {
 		return &http.Response{}, errors.Wrap(err, "Failed to construct HTTP request")
 	}
+	req.ContentLength = size
+	req = req.WithContext(c)
 
 	req.Header.Set("Authorization", APIKey)
-	req.Header.Set("CLI-Version", core.Version)
+	core.SetVersionHeaders(req)
 
-	client := http.Client{}
+	client := core.NewHTTPClient(config)
 	resp, err := client.Do(req)
 	if err != nil {
 		return &http.Response{}, errors.Wrap(err, "Failed to make request")
@@ -175,3 +569,149 @@ func postActions(ctx infra.DnoteCtx, APIKey string, payload *bytes.Buffer) (*htt
 
 	return resp, nil
 }
+
+// getStatusError returns the error to report for a response from the server
+// that is not OK
+func getStatusError(statusCode int, body []byte) error {
+	if statusCode == http.StatusUnauthorized {
+		return core.NewExitError(core.ExitAuthRequired, errors.Errorf("Unauthorized: %s. Please run `dnote login`", string(body)))
+	}
+
+	return core.NewExitError(core.ExitServerError, errors.Errorf("Server error: %s", string(body)))
+}
+
+// confirmMeteredSync asks the user whether to sync on a metered connection if
+// the changes to download are large. Their size is learned from a sync that
+// uploads nothing and whose response is not read, so that declining leaves
+// both the local data and the server as they are.
+func confirmMeteredSync(c context.Context, ctx infra.DnoteCtx, config infra.Config, apiKey string, timestamp infra.Timestamp, rate int64) (bool, error) {
+	payload, err := getPayload([]core.Action{}, timestamp)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to get dnote payload")
+	}
+
+	resp, err := postActions(c, ctx, config, apiKey, payload, rate)
+	if err != nil {
+		if err := getAbortError(c); err != nil {
+			return false, err
+		}
+
+		return false, core.NewExitError(core.ExitServerError, errors.Wrap(err, "Failed to post to the server"))
+	}
+	defer resp.Body.Close()
+	log.Debugf("size check responded with %s, request id: %s", resp.Status, core.GetRequestID(resp))
+
+	if _, err := core.CheckServerVersion(resp); err != nil {
+		return false, core.NewExitError(core.ExitServerError, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return false, errors.Wrap(err, "Failed to read failed response body")
+		}
+
+		return false, getStatusError(resp.StatusCode, body)
+	}
+
+	ok, err := confirmDownload(resp.ContentLength, config.Sync)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to confirm the download")
+	}
+
+	return ok, nil
+}
+
+// confirmDownload asks the user whether to download a response of the size in
+// bytes on a metered connection. A response of an unknown size, given as a
+// negative number, is treated as large.
+func confirmDownload(size int64, config infra.SyncConfig) (bool, error) {
+	limit := config.MeteredLimit
+	if limit <= 0 {
+		limit = defaultMeteredLimit
+	}
+
+	if size >= 0 && size <= limit {
+		return true, nil
+	}
+
+	question := "the size of the changes to download is unknown. download them on this metered connection?"
+	if size >= 0 {
+		question = fmt.Sprintf("download %s of changes on this metered connection?", utils.FormatSize(size))
+	}
+
+	return utils.AskConfirmation(question)
+}
+
+// parseBandwidth parses the bandwidth given as a number of bytes with an
+// optional unit of B, KB, or MB, into bytes per second. It returns 0 for no
+// limit if the string is empty.
+func parseBandwidth(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	str := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+
+	switch {
+	case strings.HasSuffix(str, "MB"):
+		multiplier = 1024 * 1024
+		str = strings.TrimSuffix(str, "MB")
+	case strings.HasSuffix(str, "KB"):
+		multiplier = 1024
+		str = strings.TrimSuffix(str, "KB")
+	case strings.HasSuffix(str, "B"):
+		str = strings.TrimSuffix(str, "B")
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.Errorf("Invalid bandwidth %s. Use a positive number such as 512KB or 1MB", s)
+	}
+
+	return n * multiplier, nil
+}
+
+// throttledReader limits the rate at which the underlying reader is read
+type throttledReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+// newThrottledReader returns a reader that reads at most rate bytes per
+// second from r. If rate is 0, r is returned as is.
+func newThrottledReader(r io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		return r
+	}
+
+	return &throttledReader{r: r, rate: rate}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+
+	// Read in chunks of a tenth of a second so that the rate stays smooth
+	chunk := t.rate / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	if int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := t.r.Read(p)
+	t.n += int64(n)
+
+	expected := time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second))
+	if wait := expected - time.Since(t.start); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return n, err
+}
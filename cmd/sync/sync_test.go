@@ -0,0 +1,500 @@
+package sync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// setStdin makes the given input the standard input, and returns the function
+// that restores it
+func setStdin(input string) func() {
+	r, w, err := os.Pipe()
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to create a pipe"))
+	}
+	if _, err := w.WriteString(input); err != nil {
+		panic(errors.Wrap(err, "Failed to write the input"))
+	}
+	w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+
+	return func() {
+		os.Stdin = stdin
+		r.Close()
+	}
+}
+
+// readPayload decodes the request to the sync endpoint into the bookmark and
+// the uploaded actions
+func readPayload(r *http.Request) (int, []core.Action) {
+	var payload syncPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		panic(errors.Wrap(err, "Failed to decode the payload"))
+	}
+
+	g, err := gzip.NewReader(bytes.NewReader(payload.Actions))
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the actions"))
+	}
+
+	var actions []core.Action
+	if err := json.NewDecoder(g).Decode(&actions); err != nil {
+		panic(errors.Wrap(err, "Failed to decode the actions"))
+	}
+
+	return payload.Bookmark, actions
+}
+
+// writeResponse responds to a sync with the actions and the bookmark
+func writeResponse(w http.ResponseWriter, actions []core.Action, bookmark int) {
+	b, err := json.Marshal(responseData{Actions: actions, Bookmark: bookmark})
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to marshal the response"))
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.Write(b)
+}
+
+// setupSync writes the local data to sync with the server at the endpoint
+func setupSync(ctx infra.DnoteCtx, config infra.Config, actions []core.Action, bookmark int) {
+	config.APIKey = "test-key"
+	if err := core.WriteConfig(ctx, config); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+	if err := core.WriteDnote(ctx, infra.Dnote{}); err != nil {
+		panic(errors.Wrap(err, "Failed to write dnote"))
+	}
+	if err := core.WriteActionLog(ctx, actions); err != nil {
+		panic(errors.Wrap(err, "Failed to write the action log"))
+	}
+	if err := core.WriteTimestamp(ctx, infra.Timestamp{Bookmark: bookmark}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the timestamp"))
+	}
+}
+
+func TestSync_Metered(t *testing.T) {
+	testCases := []struct {
+		input            string
+		expectedRequests int
+		expectedActions  int
+		expectedBookmark int
+	}{
+		{
+			input:            "n\n",
+			expectedRequests: 1,
+			expectedActions:  1,
+			expectedBookmark: 3,
+		},
+		{
+			input:            "y\n",
+			expectedRequests: 2,
+			expectedActions:  0,
+			expectedBookmark: 9,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			// Setup
+			ctx := testutils.InitCtx("../../tmp")
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			var uploaded [][]core.Action
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, actions := readPayload(r)
+				uploaded = append(uploaded, actions)
+
+				addBook, err := core.NewActionAddBook("linux", 1517629805)
+				if err != nil {
+					panic(errors.Wrap(err, "Failed to make the action"))
+				}
+				writeResponse(w, []core.Action{addBook}, 9)
+			}))
+			defer server.Close()
+			ctx.APIEndpoint = server.URL
+
+			action, err := core.NewActionAddBook("js", 1517629800)
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to make the action"))
+			}
+			setupSync(ctx, infra.Config{Sync: infra.SyncConfig{Metered: true, MeteredLimit: 10}}, []core.Action{action}, 3)
+
+			restoreStdin := setStdin(tc.input)
+			defer restoreStdin()
+
+			// Execute
+			if err := newRun(ctx)(nil, []string{}); err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to run sync"))
+			}
+
+			// Test
+			actions, err := core.ReadActionLog(ctx)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+			}
+			ts, err := core.ReadTimestamp(ctx)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+			}
+
+			testutils.AssertEqual(t, len(uploaded), tc.expectedRequests, "request count mismatch")
+			testutils.AssertEqual(t, len(uploaded[0]), 0, "the size check should upload nothing")
+			testutils.AssertEqual(t, len(actions), tc.expectedActions, "action log length mismatch")
+			testutils.AssertEqual(t, ts.Bookmark, tc.expectedBookmark, "bookmark mismatch")
+		})
+	}
+}
+
+func TestNewSyncContext(t *testing.T) {
+	testCases := []struct {
+		flag            time.Duration
+		config          int
+		expectedTimeout time.Duration
+	}{
+		{
+			flag:            0,
+			config:          0,
+			expectedTimeout: 0,
+		},
+		{
+			flag:            0,
+			config:          60,
+			expectedTimeout: 60 * time.Second,
+		},
+		{
+			flag:            2 * time.Minute,
+			config:          60,
+			expectedTimeout: 2 * time.Minute,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expectedTimeout.String(), func(t *testing.T) {
+			timeout = tc.flag
+			defer func() { timeout = 0 }()
+
+			// Execute
+			c, cancel := newSyncContext(infra.Config{Sync: infra.SyncConfig{Timeout: tc.config}})
+			defer cancel()
+
+			// Test
+			deadline, ok := c.Deadline()
+			testutils.AssertEqual(t, ok, tc.expectedTimeout != 0, "deadline mismatch")
+			if ok && (time.Until(deadline) > tc.expectedTimeout || time.Until(deadline) < tc.expectedTimeout-time.Second) {
+				t.Errorf("timeout mismatch. Actual: %s. Expected: %s.", time.Until(deadline), tc.expectedTimeout)
+			}
+		})
+	}
+}
+
+func TestGetAbortError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	timedOut, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	testCases := []struct {
+		name             string
+		ctx              context.Context
+		expectedError    string
+		expectedExitCode int
+	}{
+		{
+			name:             "running",
+			ctx:              context.Background(),
+			expectedError:    "",
+			expectedExitCode: core.ExitOK,
+		},
+		{
+			name:             "interrupted",
+			ctx:              canceled,
+			expectedError:    "Sync was interrupted",
+			expectedExitCode: core.ExitFailure,
+		},
+		{
+			name:             "timed out",
+			ctx:              timedOut,
+			expectedError:    "Sync timed out",
+			expectedExitCode: core.ExitServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Execute
+			err := getAbortError(tc.ctx)
+
+			// Test
+			var msg string
+			if err != nil {
+				msg = err.Error()
+			}
+			testutils.AssertEqual(t, msg, tc.expectedError, "error mismatch")
+			testutils.AssertEqual(t, core.GetExitCode(err), tc.expectedExitCode, "exit code mismatch")
+		})
+	}
+}
+
+func TestNewRequestContext(t *testing.T) {
+	t.Run("aborted before the response", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		reqCtx, _ := newRequestContext(c)
+
+		cancel()
+
+		select {
+		case <-reqCtx.Done():
+		case <-time.After(time.Second):
+			t.Error("the request should be canceled along with the sync")
+		}
+	})
+
+	t.Run("aborted after the response", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		reqCtx, detach := newRequestContext(c)
+
+		detach()
+		cancel()
+
+		select {
+		case <-reqCtx.Done():
+			t.Error("the request should not be canceled once detached")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestLocalStateRestore(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+	testutils.WriteFile(ctx, "../../testutils/fixtures/dnote1.json", "dnote")
+
+	state, err := saveLocalState(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to save the local state"))
+	}
+	testutils.WriteFile(ctx, "../../testutils/fixtures/dnote3.json", "dnote")
+	if err := core.WriteLastSync(ctx, core.SyncRecord{SyncedAt: 1}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the last sync"))
+	}
+
+	// Execute
+	if err := state.restore(); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to restore the local state"))
+	}
+
+	// Test
+	expected, err := ioutil.ReadFile("../../testutils/fixtures/dnote1.json")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the fixture"))
+	}
+
+	testutils.AssertEqual(t, string(testutils.ReadFile(ctx, "dnote")), string(expected), "dnote should be restored")
+	testutils.AssertEqual(t, utils.FileExists(core.GetLastSyncPath(ctx)), false, "files that did not exist should be removed")
+}
+
+func TestSync_AbortedAfterUpload(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	addBook, err := core.NewActionAddBook("js", 1517629800)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	setupSync(ctx, infra.Config{}, []core.Action{addBook}, 3)
+
+	remoteBook, err := core.NewActionAddBook("linux", 1517629805)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	respData := responseData{Actions: []core.Action{remoteBook}, Bookmark: 9}
+
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Execute
+	abortErr := handleResponse(c, ctx, respData, 0, false)
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+	}
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+	pending, err := core.ReadPendingActions(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the pending actions"))
+	}
+
+	testutils.AssertNotEqual(t, abortErr, nil, "the abort should be reported")
+	testutils.AssertEqual(t, len(dnote), 0, "the changes from the server should not be applied")
+	testutils.AssertEqual(t, len(actions), 0, "the uploaded changes should be cleared")
+	testutils.AssertEqual(t, ts.Bookmark, 9, "bookmark mismatch")
+	testutils.AssertEqual(t, len(pending), 1, "the changes from the server should be kept")
+
+	t.Run("next sync", func(t *testing.T) {
+		// Execute
+		if err := applyPendingActions(context.Background(), ctx); err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to apply the pending actions"))
+		}
+
+		// Test
+		dnote, err := core.GetDnote(ctx)
+		if err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+		}
+		record, err := core.ReadLastSync(ctx)
+		if err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to read the last sync"))
+		}
+
+		testutils.AssertEqual(t, len(dnote), 1, "the changes from the server should be applied")
+		testutils.AssertEqual(t, len(record.Actions), 1, "the changes should be recorded as the last sync")
+		testutils.AssertEqual(t, utils.FileExists(core.GetPendingPath(ctx)), false, "the pending actions should be cleared")
+	})
+}
+
+func TestSync_ThrottledResponse(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	var remoteActions []core.Action
+	for i, name := range []string{"linux", "go", "css", "html", "bash"} {
+		action, err := core.NewActionAddBook(name, 1517629805+int64(i))
+		if err != nil {
+			panic(errors.Wrap(err, "Failed to make the action"))
+		}
+		remoteActions = append(remoteActions, action)
+	}
+	b, err := json.Marshal(responseData{Actions: remoteActions, Bookmark: 9})
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to marshal the response"))
+	}
+
+	// Send the body at the limited rate so that it is still being received when
+	// the request timeout passes
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readPayload(r)
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+		body := newThrottledReader(bytes.NewReader(b), 200)
+		buf := make([]byte, 20)
+		for {
+			n, err := body.Read(buf)
+			w.Write(buf[:n])
+			w.(http.Flusher).Flush()
+			if err != nil {
+				break
+			}
+		}
+	}))
+	defer server.Close()
+	ctx.APIEndpoint = server.URL
+
+	addBook, err := core.NewActionAddBook("js", 1517629800)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	setupSync(ctx, infra.Config{RequestTimeout: 1}, []core.Action{addBook}, 3)
+
+	// Reading the response at this rate takes longer than the request timeout
+	maxBandwidth = "200B"
+	defer func() { maxBandwidth = "" }()
+
+	// Execute
+	if err := newRun(ctx)(nil, []string{}); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to run sync"))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the action log"))
+	}
+	ts, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+
+	testutils.AssertEqual(t, len(dnote), 5, "the changes from the server should be applied")
+	testutils.AssertEqual(t, len(actions), 0, "the uploaded changes should be cleared")
+	testutils.AssertEqual(t, ts.Bookmark, 9, "bookmark mismatch")
+}
+
+func TestGetPayload(t *testing.T) {
+	testCases := []struct {
+		name     string
+		skew     int64
+		expected []int64
+	}{
+		{name: "no skew", skew: 0, expected: []int64{1517629800, 1517629805}},
+		{name: "server ahead", skew: 300, expected: []int64{1517630100, 1517630105}},
+		{name: "server behind", skew: -300, expected: []int64{1517629500, 1517629505}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addBook, err := core.NewActionAddBook("js", 1517629800)
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to make the action"))
+			}
+			addNote, err := core.NewActionAddNote("n1", "js", "closures", 1517629805)
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to make the action"))
+			}
+
+			input := []core.Action{addBook, addNote}
+			payload, err := getPayload(input, infra.Timestamp{Bookmark: 7, ClockSkew: tc.skew})
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to get the payload"))
+			}
+
+			bookmark, actions := readPayload(httptest.NewRequest("POST", "/v1/sync", payload))
+
+			testutils.AssertEqual(t, bookmark, 7, "bookmark mismatch")
+			testutils.AssertEqual(t, len(actions), 2, "action count mismatch")
+			for i, action := range actions {
+				testutils.AssertEqual(t, action.Timestamp, tc.expected[i], fmt.Sprintf("timestamp of action %d mismatch", i))
+			}
+			testutils.AssertEqual(t, input[0].Timestamp, int64(1517629800), "the given actions should not be changed")
+			testutils.AssertEqual(t, input[1].Timestamp, int64(1517629805), "the given actions should not be changed")
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var printOnly bool
+
+var example = `
+ * Open Dnote Cloud
+ dnote web
+
+ * Open a book
+ dnote web js
+
+ * Open a note by its index in a book
+ dnote web js 3`
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if len(args) > 2 {
+		return errors.New("Incorrect number of argument")
+	}
+
+	return nil
+}
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "web <book name?> <note index?>",
+		Short:   "Open a book or a note in the browser",
+		Example: example,
+		PreRunE: preRun,
+		RunE:    newRun(ctx),
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&printOnly, "print", "p", false, "Print the URL instead of opening it")
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		config, err := core.ReadConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the config")
+		}
+
+		u, err := getURL(ctx, config.Web, args)
+		if err != nil {
+			return err
+		}
+
+		if printOnly {
+			log.Fields(u)
+			return nil
+		}
+
+		if err := utils.OpenBrowser(u); err != nil {
+			return errors.Wrap(err, "Failed to open the browser")
+		}
+
+		log.Infof("opening %s\n", u)
+		return nil
+	}
+}
+
+// getURL returns the URL of the page for the book and the note in the args.
+// The pages of the books and the notes are given by the URL templates in the
+// config, since their routes belong to the web app.
+func getURL(ctx infra.DnoteCtx, config infra.WebConfig, args []string) (string, error) {
+	if len(args) == 0 {
+		if ctx.WebEndpoint == "" {
+			return "", errors.New("The web endpoint is not configured for this build")
+		}
+
+		return ctx.WebEndpoint, nil
+	}
+
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read dnote")
+	}
+
+	bookName := core.ResolveBookName(dnote, args[0])
+	book, exists := dnote[bookName]
+	if !exists {
+		return "", errors.Errorf("Book %s does not exist", bookName)
+	}
+
+	if len(args) == 1 {
+		if config.Book == "" {
+			return "", errors.New("The URL of the page of a book is not configured. Set `book` under `web` in dnoterc")
+		}
+
+		return strings.NewReplacer("{book}", url.PathEscape(book.Name)).Replace(config.Book), nil
+	}
+
+	idx, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to parse the given index %+v", args[1])
+	}
+	if idx < 0 || idx > len(book.Notes)-1 {
+		return "", errors.Errorf("Book %s does not have note with index %d", bookName, idx)
+	}
+	if config.Note == "" {
+		return "", errors.New("The URL of the page of a note is not configured. Set `note` under `web` in dnoterc")
+	}
+
+	note := book.Notes[idx]
+	synced, err := isSynced(ctx, note)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to check if the note is synced")
+	}
+	if !synced {
+		log.Warnf("the note has not been synced yet. run `dnote sync` first\n")
+	}
+
+	return strings.NewReplacer("{uuid}", note.UUID, "{book}", url.PathEscape(book.Name)).Replace(config.Note), nil
+}
+
+// isSynced checks if the note has been uploaded to the server
+func isSynced(ctx infra.DnoteCtx, note infra.Note) (bool, error) {
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to read the action log")
+	}
+
+	for _, action := range actions {
+		if action.Type != core.ActionAddNote {
+			continue
+		}
+
+		var data core.AddNoteData
+		if err := json.Unmarshal(action.Data, &data); err != nil {
+			return false, errors.Wrap(err, "Failed to parse the action data")
+		}
+		if data.NoteUUID == note.UUID {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
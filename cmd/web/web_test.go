@@ -0,0 +1,106 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+var testWebConfig = infra.WebConfig{
+	Book: "https://example.com/books/{book}",
+	Note: "https://example.com/{book}/notes/{uuid}",
+}
+
+// setupWeb writes the local data, which has the js book with the synced note
+// n1 and the note n2 added since the last sync
+func setupWeb(ctx infra.DnoteCtx) {
+	dnote := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "closures"},
+			{UUID: "n2", Content: "hoisting"},
+		}},
+		"c#": infra.Book{Name: "c#", Notes: []infra.Note{}},
+	}
+	if err := core.WriteDnote(ctx, dnote); err != nil {
+		panic(errors.Wrap(err, "Failed to write dnote"))
+	}
+
+	action, err := core.NewActionAddNote("n2", "js", "hoisting", 1517629805)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to make the action"))
+	}
+	if err := core.WriteActionLog(ctx, []core.Action{action}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the action log"))
+	}
+}
+
+func TestGetURL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      infra.WebConfig
+		args        []string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "home", config: testWebConfig, args: []string{}, expected: "https://example.com"},
+		{name: "book", config: testWebConfig, args: []string{"JS"}, expected: "https://example.com/books/js"},
+		{name: "escaped book", config: testWebConfig, args: []string{"c#"}, expected: "https://example.com/books/c%23"},
+		{name: "note", config: testWebConfig, args: []string{"js", "0"}, expected: "https://example.com/js/notes/n1"},
+		{name: "missing book", config: testWebConfig, args: []string{"go"}, expectedErr: true},
+		{name: "invalid index", config: testWebConfig, args: []string{"js", "2"}, expectedErr: true},
+		{name: "book page not configured", config: infra.WebConfig{}, args: []string{"js"}, expectedErr: true},
+		{name: "note page not configured", config: infra.WebConfig{}, args: []string{"js", "0"}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := testutils.InitCtx("../../tmp")
+			ctx.WebEndpoint = "https://example.com"
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			setupWeb(ctx)
+
+			// Execute
+			got, err := getURL(ctx, tc.config, tc.args)
+
+			// Test
+			testutils.AssertEqual(t, err != nil, tc.expectedErr, "error mismatch")
+			testutils.AssertEqual(t, got, tc.expected, "URL mismatch")
+		})
+	}
+}
+
+func TestIsSynced(t *testing.T) {
+	testCases := []struct {
+		uuid     string
+		expected bool
+	}{
+		{uuid: "n1", expected: true},
+		{uuid: "n2", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.uuid, func(t *testing.T) {
+			// Setup
+			ctx := testutils.InitCtx("../../tmp")
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			setupWeb(ctx)
+
+			// Execute
+			got, err := isSynced(ctx, infra.Note{UUID: tc.uuid})
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to check if the note is synced"))
+			}
+
+			// Test
+			testutils.AssertEqual(t, got, tc.expected, "result mismatch")
+		})
+	}
+}
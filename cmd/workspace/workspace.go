@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"os"
+
+	"github.com/dnote-io/cli/core"
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var example = `
+ * Use the book 'dnote' for notes taken under the current directory
+ dnote workspace init dnote
+
+ * Print the book of the current workspace
+ dnote workspace`
+
+func NewCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "workspace",
+		Short:   "Show or set the default book for the current directory",
+		Example: example,
+		RunE:    newRun(ctx),
+	}
+
+	cmd.AddCommand(newInitCmd(ctx))
+
+	return cmd
+}
+
+func newRun(ctx infra.DnoteCtx) core.RunEFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		workspace, err := core.ReadWorkspace()
+		if err != nil {
+			return errors.Wrap(err, "Failed to read the workspace")
+		}
+
+		if workspace.Book == "" {
+			log.Plain("not in a workspace\n")
+			return nil
+		}
+
+		if log.Porcelain {
+			log.Fields(workspace.Book)
+			return nil
+		}
+
+		log.Infof("book: %s\n", workspace.Book)
+		return nil
+	}
+}
+
+func newInitCmd(ctx infra.DnoteCtx) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init <book name>",
+		Short: "Make the current directory a workspace for the book",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Incorrect number of argument")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wd, err := os.Getwd()
+			if err != nil {
+				return errors.Wrap(err, "Failed to get the working directory")
+			}
+
+			if err := core.ValidateBookName(args[0]); err != nil {
+				return err
+			}
+
+			workspace := core.Workspace{Book: args[0]}
+			if err := core.WriteWorkspace(wd, workspace); err != nil {
+				return errors.Wrap(err, "Failed to write the workspace")
+			}
+
+			log.Successf("notes taken in %s will be added to %s\n", wd, workspace.Book)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -23,20 +23,30 @@ type Action struct {
 	Timestamp int64           `json:"timestamp"`
 }
 
-func LogActionAddNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, timestamp int64) error {
+// NewActionAddNote returns an action for adding the note to the book
+func NewActionAddNote(noteUUID, bookName, content string, ts int64) (Action, error) {
 	b, err := json.Marshal(AddNoteData{
 		NoteUUID: noteUUID,
 		BookName: bookName,
 		Content:  content,
 	})
 	if err != nil {
-		return errors.Wrap(err, "Failed to marshal data into JSON")
+		return Action{}, errors.Wrap(err, "Failed to marshal data into JSON")
 	}
 
 	action := Action{
 		Type:      ActionAddNote,
 		Data:      b,
-		Timestamp: timestamp,
+		Timestamp: ts,
+	}
+
+	return action, nil
+}
+
+func LogActionAddNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, timestamp int64) error {
+	action, err := NewActionAddNote(noteUUID, bookName, content, timestamp)
+	if err != nil {
+		return err
 	}
 
 	if err := LogAction(ctx, action); err != nil {
@@ -46,19 +56,29 @@ func LogActionAddNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, ti
 	return nil
 }
 
-func LogActionRemoveNote(ctx infra.DnoteCtx, noteUUID, bookName string) error {
+// NewActionRemoveNote returns an action for removing the note from the book
+func NewActionRemoveNote(noteUUID, bookName string, ts int64) (Action, error) {
 	b, err := json.Marshal(RemoveNoteData{
 		NoteUUID: noteUUID,
 		BookName: bookName,
 	})
 	if err != nil {
-		return errors.Wrap(err, "Failed to marshal data into JSON")
+		return Action{}, errors.Wrap(err, "Failed to marshal data into JSON")
 	}
 
 	action := Action{
 		Type:      ActionRemoveNote,
 		Data:      b,
-		Timestamp: time.Now().Unix(),
+		Timestamp: ts,
+	}
+
+	return action, nil
+}
+
+func LogActionRemoveNote(ctx infra.DnoteCtx, noteUUID, bookName string) error {
+	action, err := NewActionRemoveNote(noteUUID, bookName, time.Now().Unix())
+	if err != nil {
+		return err
 	}
 
 	if err := LogAction(ctx, action); err != nil {
@@ -68,14 +88,15 @@ func LogActionRemoveNote(ctx infra.DnoteCtx, noteUUID, bookName string) error {
 	return nil
 }
 
-func LogActionEditNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, ts int64) error {
+// NewActionEditNote returns an action for editing the content of the note
+func NewActionEditNote(noteUUID, bookName, content string, ts int64) (Action, error) {
 	b, err := json.Marshal(EditNoteData{
 		NoteUUID: noteUUID,
 		BookName: bookName,
 		Content:  content,
 	})
 	if err != nil {
-		return errors.Wrap(err, "Failed to marshal data into JSON")
+		return Action{}, errors.Wrap(err, "Failed to marshal data into JSON")
 	}
 
 	action := Action{
@@ -84,6 +105,15 @@ func LogActionEditNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, t
 		Timestamp: ts,
 	}
 
+	return action, nil
+}
+
+func LogActionEditNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, ts int64) error {
+	action, err := NewActionEditNote(noteUUID, bookName, content, ts)
+	if err != nil {
+		return err
+	}
+
 	if err := LogAction(ctx, action); err != nil {
 		return errors.Wrapf(err, "Failed to log action type %s", ActionEditNote)
 	}
@@ -91,18 +121,28 @@ func LogActionEditNote(ctx infra.DnoteCtx, noteUUID, bookName, content string, t
 	return nil
 }
 
-func LogActionAddBook(ctx infra.DnoteCtx, name string) error {
+// NewActionAddBook returns an action for adding the book
+func NewActionAddBook(name string, ts int64) (Action, error) {
 	b, err := json.Marshal(AddBookData{
 		BookName: name,
 	})
 	if err != nil {
-		return errors.Wrap(err, "Failed to marshal data into JSON")
+		return Action{}, errors.Wrap(err, "Failed to marshal data into JSON")
 	}
 
 	action := Action{
 		Type:      ActionAddBook,
 		Data:      b,
-		Timestamp: time.Now().Unix(),
+		Timestamp: ts,
+	}
+
+	return action, nil
+}
+
+func LogActionAddBook(ctx infra.DnoteCtx, name string) error {
+	action, err := NewActionAddBook(name, time.Now().Unix())
+	if err != nil {
+		return err
 	}
 
 	if err := LogAction(ctx, action); err != nil {
@@ -112,16 +152,26 @@ func LogActionAddBook(ctx infra.DnoteCtx, name string) error {
 	return nil
 }
 
-func LogActionRemoveBook(ctx infra.DnoteCtx, name string) error {
+// NewActionRemoveBook returns an action for removing the book and its notes
+func NewActionRemoveBook(name string, ts int64) (Action, error) {
 	b, err := json.Marshal(RemoveBookData{BookName: name})
 	if err != nil {
-		return errors.Wrap(err, "Failed to marshal data into JSON")
+		return Action{}, errors.Wrap(err, "Failed to marshal data into JSON")
 	}
 
 	action := Action{
 		Type:      ActionRemoveBook,
 		Data:      b,
-		Timestamp: time.Now().Unix(),
+		Timestamp: ts,
+	}
+
+	return action, nil
+}
+
+func LogActionRemoveBook(ctx infra.DnoteCtx, name string) error {
+	action, err := NewActionRemoveBook(name, time.Now().Unix())
+	if err != nil {
+		return err
 	}
 
 	if err := LogAction(ctx, action); err != nil {
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"path/filepath"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/keychain"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+)
+
+// keyStore is the keychain storing the API key. Tests replace it so that they
+// never touch the keychain of the user.
+var keyStore keychain.Store = keychain.System{}
+
+func useKeychain(config infra.Config) bool {
+	return !config.DisableKeychain && keyStore.Available()
+}
+
+// getKeychainAccount returns the account of the API key in the keychain. It is
+// scoped to the dnote directory so that each directory keeps its own key.
+func getKeychainAccount(ctx infra.DnoteCtx) (string, error) {
+	dir, err := filepath.Abs(ctx.DnoteDir)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to get the absolute path of the dnote directory")
+	}
+
+	return "api_key:" + dir, nil
+}
+
+// ReadAPIKey returns the API key from the config file or, if it is not
+// there, from the keychain
+func ReadAPIKey(ctx infra.DnoteCtx) (string, error) {
+	config, err := ReadConfig(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read the config")
+	}
+
+	if config.APIKey != "" || !useKeychain(config) {
+		return config.APIKey, nil
+	}
+
+	account, err := getKeychainAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	apiKey, err := keyStore.Get(account)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read the API key from the keychain")
+	}
+
+	return apiKey, nil
+}
+
+// WriteAPIKey persists the API key in the keychain if available, and in the
+// config file otherwise. The config file is also used, with a warning, if the
+// keychain fails to store the key, such as when no Secret Service is running.
+func WriteAPIKey(ctx infra.DnoteCtx, apiKey string) error {
+	config, err := ReadConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the config")
+	}
+
+	config.APIKey = apiKey
+
+	if useKeychain(config) {
+		account, err := getKeychainAccount(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := keyStore.Set(account, apiKey); err != nil {
+			log.Warnf("failed to store the API key in the keychain. storing it in dnoterc instead: %s\n", err.Error())
+		} else {
+			config.APIKey = ""
+		}
+	}
+
+	if err := WriteConfig(ctx, config); err != nil {
+		return errors.Wrap(err, "Failed to write the config")
+	}
+
+	return nil
+}
+
+// MigrateAPIKey moves an API key stored in plaintext in the config file into
+// the keychain. The key is left in place if the keychain cannot store it.
+func MigrateAPIKey(ctx infra.DnoteCtx) error {
+	config, err := ReadConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the config")
+	}
+
+	if config.APIKey == "" || !useKeychain(config) {
+		return nil
+	}
+
+	account, err := getKeychainAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := keyStore.Set(account, config.APIKey); err != nil {
+		return nil
+	}
+
+	config.APIKey = ""
+	if err := WriteConfig(ctx, config); err != nil {
+		return errors.Wrap(err, "Failed to write the config")
+	}
+
+	return nil
+}
+
+// ClearAPIKey removes the API key from both the config file and the keychain
+func ClearAPIKey(ctx infra.DnoteCtx) error {
+	config, err := ReadConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the config")
+	}
+
+	if useKeychain(config) {
+		account, err := getKeychainAccount(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := keyStore.Delete(account); err != nil {
+			return errors.Wrap(err, "Failed to delete the API key from the keychain")
+		}
+	}
+
+	config.APIKey = ""
+	if err := WriteConfig(ctx, config); err != nil {
+		return errors.Wrap(err, "Failed to write the config")
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+// testKeyStore is a keychain kept in memory
+type testKeyStore struct {
+	secrets map[string]string
+	setErr  error
+}
+
+func (s *testKeyStore) Available() bool {
+	return true
+}
+
+func (s *testKeyStore) Get(account string) (string, error) {
+	return s.secrets[account], nil
+}
+
+func (s *testKeyStore) Set(account, secret string) error {
+	if s.setErr != nil {
+		return s.setErr
+	}
+
+	s.secrets[account] = secret
+	return nil
+}
+
+func (s *testKeyStore) Delete(account string) error {
+	delete(s.secrets, account)
+	return nil
+}
+
+// setKeyStore replaces the keychain with the store and returns a function
+// restoring the original
+func setKeyStore(store *testKeyStore) func() {
+	original := keyStore
+	keyStore = store
+
+	return func() {
+		keyStore = original
+	}
+}
+
+func TestWriteAPIKey(t *testing.T) {
+	testCases := []struct {
+		setErr         error
+		expectedConfig string
+		expectedStored int
+	}{
+		{
+			setErr:         nil,
+			expectedConfig: "",
+			expectedStored: 1,
+		},
+		{
+			setErr:         errors.New("no Secret Service is running"),
+			expectedConfig: "test-key",
+			expectedStored: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		func() {
+			// Setup
+			ctx := testutils.InitCtx("../tmp")
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			store := &testKeyStore{secrets: map[string]string{}, setErr: tc.setErr}
+			defer setKeyStore(store)()
+
+			if err := WriteConfig(ctx, infra.Config{}); err != nil {
+				panic(errors.Wrap(err, "Failed to write the config"))
+			}
+
+			// Execute
+			if err := WriteAPIKey(ctx, "test-key"); err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to write the API key"))
+			}
+
+			// Test
+			config, err := ReadConfig(ctx)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to read the config"))
+			}
+			apiKey, err := ReadAPIKey(ctx)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to read the API key"))
+			}
+
+			testutils.AssertEqual(t, config.APIKey, tc.expectedConfig, "API key in the config mismatch")
+			testutils.AssertEqual(t, len(store.secrets), tc.expectedStored, "stored key count mismatch")
+			testutils.AssertEqual(t, apiKey, "test-key", "API key mismatch")
+		}()
+	}
+}
+
+func TestMigrateAPIKey(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	store := &testKeyStore{secrets: map[string]string{"api_key:/other/.dnote": "other-key"}}
+	defer setKeyStore(store)()
+
+	if err := WriteConfig(ctx, infra.Config{APIKey: "test-key"}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+
+	// Execute
+	if err := MigrateAPIKey(ctx); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to migrate the API key"))
+	}
+
+	// Test
+	config, err := ReadConfig(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the config"))
+	}
+	account, err := getKeychainAccount(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get the account"))
+	}
+
+	testutils.AssertEqual(t, config.APIKey, "", "API key should be removed from the config")
+	testutils.AssertEqual(t, store.secrets[account], "test-key", "API key mismatch")
+	testutils.AssertEqual(t, store.secrets["api_key:/other/.dnote"], "other-key", "the key of another directory should be kept")
+}
+
+func TestClearAPIKey(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	store := &testKeyStore{secrets: map[string]string{"api_key:/other/.dnote": "other-key"}}
+	defer setKeyStore(store)()
+
+	if err := WriteConfig(ctx, infra.Config{}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+	if err := WriteAPIKey(ctx, "test-key"); err != nil {
+		panic(errors.Wrap(err, "Failed to write the API key"))
+	}
+
+	// Execute
+	if err := ClearAPIKey(ctx); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to clear the API key"))
+	}
+
+	// Test
+	apiKey, err := ReadAPIKey(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the API key"))
+	}
+
+	testutils.AssertEqual(t, apiKey, "", "API key should be cleared")
+	testutils.AssertDeepEqual(t, store.secrets, map[string]string{"api_key:/other/.dnote": "other-key"}, "the key of another directory should be kept")
+}
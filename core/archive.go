@@ -0,0 +1,58 @@
+package core
+
+import (
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/pkg/errors"
+)
+
+// ArchiveVersion is the version of the book archive format
+const ArchiveVersion = 1
+
+// BookArchive is a self-contained representation of a book and its notes
+type BookArchive struct {
+	Version    int        `json:"version"`
+	ExportedOn int64      `json:"exported_on"`
+	Book       infra.Book `json:"book"`
+}
+
+// NewBookArchive returns an archive of the given book
+func NewBookArchive(book infra.Book) BookArchive {
+	return BookArchive{
+		Version:    ArchiveVersion,
+		ExportedOn: time.Now().Unix(),
+		Book:       book,
+	}
+}
+
+// ImportNotes adds notes with the given contents to the book with the given
+// name, creating the book if it does not exist. Every note is given a new
+// UUID and logged as an action so that it is uploaded on the next sync. It
+// returns the number of notes added.
+func ImportNotes(ctx infra.DnoteCtx, dnote infra.Dnote, bookName string, contents []string) (int, error) {
+	book, ok := dnote[bookName]
+	if !ok {
+		book = NewBook(bookName)
+
+		if err := LogActionAddBook(ctx, bookName); err != nil {
+			return 0, errors.Wrap(err, "Failed to log action")
+		}
+	}
+
+	ts := time.Now().Unix()
+	notes := book.Notes
+
+	for _, content := range contents {
+		note := NewNote(content, ts)
+		notes = append(notes, note)
+
+		if err := LogActionAddNote(ctx, note.UUID, bookName, note.Content, ts); err != nil {
+			return 0, errors.Wrap(err, "Failed to log action")
+		}
+	}
+
+	dnote[bookName] = GetUpdatedBook(book, notes)
+
+	return len(contents), nil
+}
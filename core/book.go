@@ -0,0 +1,71 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/pkg/errors"
+)
+
+// LessBookName reports whether the book name a sorts before b. The names are
+// compared ignoring case so that "Go" and "go" are listed next to each other,
+// and then as they are so that the order is deterministic.
+func LessBookName(a, b string) bool {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	if la != lb {
+		return la < lb
+	}
+
+	return a < b
+}
+
+// SortBookNames sorts the book names in place using LessBookName
+func SortBookNames(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		return LessBookName(names[i], names[j])
+	})
+}
+
+// ResolveBookName returns the name of the book in dnote matching the given
+// name ignoring case, so that the casing the book was created with is kept.
+// The name is returned as is if it matches exactly or no book matches.
+func ResolveBookName(dnote infra.Dnote, name string) string {
+	if _, ok := dnote[name]; ok {
+		return name
+	}
+
+	for bookName := range dnote {
+		if strings.EqualFold(bookName, name) {
+			return bookName
+		}
+	}
+
+	return name
+}
+
+// ValidateBookName returns an error if the name cannot be used for a new
+// book because it could be mistaken for other arguments of the commands
+func ValidateBookName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("Book name cannot be empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return errors.Errorf("Book name %s cannot be a number because it would be mistaken for a note index", name)
+	}
+	if strings.HasPrefix(name, "-") {
+		return errors.Errorf("Book name %s cannot start with a dash because it would be mistaken for a flag", name)
+	}
+	if strings.Contains(name, "/") {
+		return errors.Errorf("Book name %s cannot contain a slash", name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return errors.Errorf("Book name %q cannot contain control characters", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestSortBookNames(t *testing.T) {
+	names := []string{"linux", "Go", "Élan", "go", "algorithms", "Docker"}
+
+	SortBookNames(names)
+
+	expected := []string{"algorithms", "Docker", "Go", "go", "linux", "Élan"}
+	testutils.AssertDeepEqual(t, names, expected, "order mismatch")
+}
+
+func TestResolveBookName(t *testing.T) {
+	dnote := infra.Dnote{
+		"JavaScript": NewBook("JavaScript"),
+		"go":         NewBook("go"),
+	}
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "javascript", expected: "JavaScript"},
+		{name: "JAVASCRIPT", expected: "JavaScript"},
+		{name: "go", expected: "go"},
+		{name: "Go", expected: "go"},
+		{name: "linux", expected: "linux"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.AssertEqual(t, ResolveBookName(dnote, tc.name), tc.expected, "book name mismatch")
+		})
+	}
+}
+
+func TestValidateBookName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "javascript", valid: true},
+		{name: "c++", valid: true},
+		{name: "node 8", valid: true},
+		{name: "", valid: false},
+		{name: "  ", valid: false},
+		{name: "123", valid: false},
+		{name: "-b", valid: false},
+		{name: "js/react", valid: false},
+		{name: "js\tnode", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBookName(tc.name)
+			testutils.AssertEqual(t, err == nil, tc.valid, "validity mismatch")
+		})
+	}
+}
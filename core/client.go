@@ -0,0 +1,141 @@
+package core
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/pkg/errors"
+)
+
+// DefaultRequestTimeout is how long to wait for the server to respond to a
+// request unless set in the config
+var DefaultRequestTimeout = 60 * time.Second
+
+// ProtocolVersion is the version of the sync protocol spoken by the CLI. The
+// server uses it along with the CLI version to tell if it can serve the CLI.
+var ProtocolVersion = 1
+
+// clockSkewThreshold is the number of seconds by which the local clock can
+// differ from the server clock before the user is warned
+var clockSkewThreshold int64 = 5 * 60
+
+// NewHTTPClient returns a client for the requests to the server, which gives
+// up on connecting to the server or waiting for its response after the request
+// timeout in the config. Reading the body is not limited, so that a throttled
+// download is not cut short, and is canceled through the request context.
+func NewHTTPClient(config infra.Config) *http.Client {
+	timeout := DefaultRequestTimeout
+	if config.RequestTimeout > 0 {
+		timeout = time.Duration(config.RequestTimeout) * time.Second
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// GetClockSkew returns the number of seconds the server clock is ahead of the
+// local clock, estimated from the Date header of the response. The second
+// return value is false if the server did not send the header.
+func GetClockSkew(resp *http.Response, requestedAt, receivedAt time.Time) (int64, bool) {
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+
+	// Assume the server handled the request halfway through the round trip
+	localTime := requestedAt.Add(receivedAt.Sub(requestedAt) / 2)
+
+	return int64(serverTime.Sub(localTime).Seconds()), true
+}
+
+// IsClockSkewed checks if the clock skew in seconds is large enough to warn
+// the user about
+func IsClockSkewed(skew int64) bool {
+	return skew > clockSkewThreshold || skew < -clockSkewThreshold
+}
+
+// GetRequestID returns the identifier the server gave to the request, which
+// helps to find the request in the server logs when reporting a problem
+func GetRequestID(resp *http.Response) string {
+	return resp.Header.Get("X-Request-Id")
+}
+
+// SetVersionHeaders sets the headers telling the server the version of the CLI
+// and of the protocol it speaks. They are sent with every request.
+func SetVersionHeaders(req *http.Request) {
+	req.Header.Set("CLI-Version", Version)
+	req.Header.Set("CLI-Protocol", strconv.Itoa(ProtocolVersion))
+}
+
+// CheckServerVersion checks the compatibility information in the response
+// from the server. It returns an error if the server no longer supports this
+// version of the CLI, and true if a newer version is available.
+func CheckServerVersion(resp *http.Response) (bool, error) {
+	minVersion := resp.Header.Get("Min-CLI-Version")
+
+	if resp.StatusCode == http.StatusUpgradeRequired || (minVersion != "" && compareVersions(Version, minVersion) < 0) {
+		msg := "The server no longer supports dnote %s. Run `dnote upgrade` to upgrade"
+		if minVersion != "" {
+			return false, errors.Errorf(msg+" to %s or later", Version, minVersion)
+		}
+
+		return false, errors.Errorf(msg, Version)
+	}
+
+	latestVersion := resp.Header.Get("Latest-CLI-Version")
+	if latestVersion != "" && compareVersions(Version, latestVersion) < 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// compareVersions compares the versions in the major.minor.patch form and
+// returns -1, 0, or 1 if a is older than, the same as, or newer than b. A
+// leading v and a pre-release suffix are ignored, as are parts that are not
+// numbers.
+func compareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+
+	for i := 0; i < 3; i++ {
+		if pa[i] < pb[i] {
+			return -1
+		}
+		if pa[i] > pb[i] {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func parseVersion(v string) [3]int {
+	var ret [3]int
+
+	v = strings.TrimPrefix(v, "v")
+	v = strings.SplitN(v, "-", 2)[0]
+
+	for i, part := range strings.SplitN(v, ".", 3) {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			continue
+		}
+
+		ret[i] = n
+	}
+
+	return ret
+}
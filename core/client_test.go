@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "0.2.0", b: "0.2.0", expected: 0},
+		{a: "0.2.0", b: "0.2.1", expected: -1},
+		{a: "0.10.0", b: "0.9.3", expected: 1},
+		{a: "v1.0.0", b: "1.0.0", expected: 0},
+		{a: "1.0.0-beta", b: "1.0.0", expected: 0},
+		{a: "1.2", b: "1.2.0", expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s", tc.a, tc.b), func(t *testing.T) {
+			testutils.AssertEqual(t, compareVersions(tc.a, tc.b), tc.expected, "result mismatch")
+		})
+	}
+}
+
+func TestCheckServerVersion(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		newer      bool
+		compatible bool
+	}{
+		{name: "no information", status: http.StatusOK, compatible: true},
+		{name: "supported", status: http.StatusOK, header: map[string]string{"Min-CLI-Version": "0.1.0", "Latest-CLI-Version": Version}, compatible: true},
+		{name: "newer available", status: http.StatusOK, header: map[string]string{"Latest-CLI-Version": "99.0.0"}, newer: true, compatible: true},
+		{name: "too old", status: http.StatusOK, header: map[string]string{"Min-CLI-Version": "99.0.0"}, compatible: false},
+		{name: "upgrade required", status: http.StatusUpgradeRequired, compatible: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			for k, v := range tc.header {
+				resp.Header.Set(k, v)
+			}
+
+			newer, err := CheckServerVersion(resp)
+
+			testutils.AssertEqual(t, newer, tc.newer, "newer mismatch")
+			testutils.AssertEqual(t, err == nil, tc.compatible, "compatibility mismatch")
+		})
+	}
+}
+
+func TestGetClockSkew(t *testing.T) {
+	requestedAt := time.Date(2018, 2, 3, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		date       string
+		roundTrip  time.Duration
+		expected   int64
+		expectedOK bool
+	}{
+		{name: "in sync", date: "Sat, 03 Feb 2018 10:00:01 GMT", roundTrip: 2 * time.Second, expected: 0, expectedOK: true},
+		{name: "server ahead", date: "Sat, 03 Feb 2018 10:05:01 GMT", roundTrip: 2 * time.Second, expected: 300, expectedOK: true},
+		{name: "server behind", date: "Sat, 03 Feb 2018 09:55:01 GMT", roundTrip: 2 * time.Second, expected: -300, expectedOK: true},
+		{name: "slow round trip", date: "Sat, 03 Feb 2018 10:00:30 GMT", roundTrip: 60 * time.Second, expected: 0, expectedOK: true},
+		{name: "no date", date: "", roundTrip: time.Second, expected: 0, expectedOK: false},
+		{name: "invalid date", date: "yesterday", roundTrip: time.Second, expected: 0, expectedOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tc.date != "" {
+				resp.Header.Set("Date", tc.date)
+			}
+
+			skew, ok := GetClockSkew(resp, requestedAt, requestedAt.Add(tc.roundTrip))
+
+			testutils.AssertEqual(t, skew, tc.expected, "skew mismatch")
+			testutils.AssertEqual(t, ok, tc.expectedOK, "ok mismatch")
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"strings"
+)
+
+// CodeBlock is a fenced code block in the content of a note
+type CodeBlock struct {
+	// Lang is the language given after the opening fence, if any
+	Lang string
+	Code string
+}
+
+// getFence returns the fence that the line opens or closes, which is a run of
+// at least three backticks or tildes
+func getFence(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) < 3 {
+		return ""
+	}
+
+	c := line[0]
+	if c != '`' && c != '~' {
+		return ""
+	}
+
+	n := 0
+	for n < len(line) && line[n] == c {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+
+	return line[:n]
+}
+
+// GetCodeBlocks returns the fenced code blocks in the content in the order they
+// appear. An unclosed block runs until the end of the content.
+func GetCodeBlocks(content string) []CodeBlock {
+	var ret []CodeBlock
+
+	var fence string
+	var block CodeBlock
+	var lines []string
+
+	for _, line := range strings.Split(content, "\n") {
+		if fence == "" {
+			f := getFence(line)
+			if f == "" {
+				continue
+			}
+
+			fence = f
+			block = CodeBlock{Lang: strings.ToLower(strings.TrimSpace(strings.TrimSpace(line)[len(f):]))}
+			lines = nil
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			block.Code = strings.Join(lines, "\n")
+			ret = append(ret, block)
+			fence = ""
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if fence != "" {
+		block.Code = strings.Join(lines, "\n")
+		ret = append(ret, block)
+	}
+
+	return ret
+}
+
+// GetCodeBlocksByLang returns the code blocks in the content written in the
+// language, ignoring case. If lang is empty, all code blocks are returned.
+func GetCodeBlocksByLang(content, lang string) []CodeBlock {
+	blocks := GetCodeBlocks(content)
+	if lang == "" {
+		return blocks
+	}
+
+	var ret []CodeBlock
+	for _, b := range blocks {
+		if b.Lang == strings.ToLower(lang) {
+			ret = append(ret, b)
+		}
+	}
+
+	return ret
+}
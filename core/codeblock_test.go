@@ -0,0 +1,26 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestGetCodeBlocks(t *testing.T) {
+	content := "print the pods\n```sh\nkubectl get pods \\\n  -o 'jsonpath={.items[*].metadata.name}'\n```\nin Go:\n~~~~ Go\nfmt.Println(\"```\")\n~~~~\n```\nunclosed"
+
+	blocks := GetCodeBlocks(content)
+
+	testutils.AssertEqual(t, len(blocks), 3, "block count mismatch")
+	testutils.AssertEqual(t, blocks[0].Lang, "sh", "lang mismatch")
+	testutils.AssertEqual(t, blocks[0].Code, "kubectl get pods \\\n  -o 'jsonpath={.items[*].metadata.name}'", "code mismatch")
+	testutils.AssertEqual(t, blocks[1].Lang, "go", "lang mismatch")
+	testutils.AssertEqual(t, blocks[1].Code, "fmt.Println(\"```\")", "code mismatch")
+	testutils.AssertEqual(t, blocks[2].Lang, "", "lang mismatch")
+	testutils.AssertEqual(t, blocks[2].Code, "unclosed", "code mismatch")
+
+	goBlocks := GetCodeBlocksByLang(content, "GO")
+	testutils.AssertEqual(t, len(goBlocks), 1, "go block count mismatch")
+
+	testutils.AssertEqual(t, len(GetCodeBlocks("no code here")), 0, "block count mismatch")
+}
@@ -28,6 +28,9 @@ const (
 	DnoteFilename      = "dnote"
 	ActionFilename     = "actions"
 	TmpContentFilename = "DNOTE_TMPCONTENT"
+	// SchemaFilename is the name of the file in which the migrate package
+	// records the migrations that have been run
+	SchemaFilename = "schema"
 )
 
 type RunEFunc func(*cobra.Command, []string) error
@@ -289,6 +292,31 @@ func LogAction(ctx infra.DnoteCtx, action Action) error {
 	return nil
 }
 
+// LogActions appends the actions to the action log at once and updates the
+// last_action timestamp
+func LogActions(ctx infra.DnoteCtx, actions []Action) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	existing, err := ReadActionLog(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the action log")
+	}
+
+	err = WriteActionLog(ctx, append(existing, actions...))
+	if err != nil {
+		return errors.Wrap(err, "Failed to write action log")
+	}
+
+	err = UpdateLastActionTimestamp(ctx, actions[len(actions)-1].Timestamp)
+	if err != nil {
+		return errors.Wrap(err, "Failed to update the last_action timestamp")
+	}
+
+	return nil
+}
+
 func WriteActionLog(ctx infra.DnoteCtx, actions []Action) error {
 	path := GetActionPath(ctx)
 
@@ -381,10 +409,23 @@ func NewNote(content string, ts int64) infra.Note {
 	return infra.Note{
 		UUID:    utils.GenerateUID(),
 		Content: content,
+		Title:   GetTitle(content),
 		AddedOn: ts,
 	}
 }
 
+// GetTitle returns the title of a note with the content, which is its first
+// non-empty line
+func GetTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
 // NewBook returns a book
 func NewBook(name string) infra.Book {
 	return infra.Book{
@@ -467,6 +508,15 @@ func IsFreshInstall(ctx infra.DnoteCtx) (bool, error) {
 	return false, nil
 }
 
+// GetLastActivity returns the time the note was last added or edited
+func GetLastActivity(note infra.Note) int64 {
+	if note.EditedOn > note.AddedOn {
+		return note.EditedOn
+	}
+
+	return note.AddedOn
+}
+
 func FilterNotes(notes []infra.Note, testFunc func(infra.Note) bool) []infra.Note {
 	var ret []infra.Note
 
@@ -479,13 +529,45 @@ func FilterNotes(notes []infra.Note, testFunc func(infra.Note) bool) []infra.Not
 	return ret
 }
 
-// SanitizeContent sanitizes note content
-func SanitizeContent(s string) string {
-	var ret string
+// waitWithAutosave waits for the command to exit, calling save with the
+// content of the file every autosaveInterval if it changed
+func waitWithAutosave(cmd *exec.Cmd, fpath string, save func(string)) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var saved string
+	autosave := func() {
+		b, err := ioutil.ReadFile(fpath)
+		if err != nil || string(b) == saved {
+			return
+		}
+
+		saved = string(b)
+		save(saved)
+	}
 
-	ret = strings.Replace(s, "\n", "", -1)
-	ret = strings.Replace(ret, "\r\n", "", -1)
-	ret = strings.Trim(ret, " ")
+	ticker := time.NewTicker(autosaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			autosave()
+			return err
+		case <-ticker.C:
+			autosave()
+		}
+	}
+}
+
+// SanitizeContent sanitizes note content by using "\n" for line breaks and
+// trimming the surrounding whitespace. The line breaks within are kept, since
+// the first line is the title and code blocks span several lines.
+func SanitizeContent(s string) string {
+	ret := strings.Replace(s, "\r\n", "\n", -1)
+	ret = strings.TrimSpace(ret)
 
 	return ret
 }
@@ -502,9 +584,19 @@ func getEditorCmd(ctx infra.DnoteCtx, fpath string) (*exec.Cmd, error) {
 	return exec.Command(args[0], args[1:]...), nil
 }
 
+// autosaveInterval is how often the content written in the editor is saved
+var autosaveInterval = 3 * time.Second
+
 // GetEditorInput gets the user input by launching a text editor and waiting for
 // it to exit
 func GetEditorInput(ctx infra.DnoteCtx, fpath string, content *string) error {
+	return GetEditorInputWithAutosave(ctx, fpath, content, nil)
+}
+
+// GetEditorInputWithAutosave is like GetEditorInput, but also calls save with
+// the content of the file whenever it changes while the editor is open, and
+// once more after the editor exits, even if it failed
+func GetEditorInputWithAutosave(ctx infra.DnoteCtx, fpath string, content *string, save func(string)) error {
 	if !utils.FileExists(fpath) {
 		f, err := os.Create(fpath)
 		if err != nil {
@@ -530,7 +622,11 @@ func GetEditorInput(ctx infra.DnoteCtx, fpath string, content *string) error {
 		return errors.Wrapf(err, "Failed to launch the editor")
 	}
 
-	err = cmd.Wait()
+	if save == nil {
+		err = cmd.Wait()
+	} else {
+		err = waitWithAutosave(cmd, fpath, save)
+	}
 	if err != nil {
 		return errors.Wrap(err, "Failed to wait for the editor")
 	}
@@ -85,3 +85,9 @@ func TestMigrateToDnoteDir(t *testing.T) {
 		}
 	})
 }
+
+func TestGetTitle(t *testing.T) {
+	testutils.AssertEqual(t, GetTitle("closures capture variables"), "closures capture variables", "title mismatch")
+	testutils.AssertEqual(t, GetTitle("\n  undo the last commit \ngit reset --soft HEAD~1"), "undo the last commit", "title mismatch")
+	testutils.AssertEqual(t, GetTitle(" \n "), "", "title mismatch")
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"strings"
+)
+
+const (
+	// DiffEqual marks a line present in both texts
+	DiffEqual = ' '
+	// DiffDelete marks a line only present in the old text
+	DiffDelete = '-'
+	// DiffInsert marks a line only present in the new text
+	DiffInsert = '+'
+)
+
+// DiffLine is a line in the difference between two texts
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// DiffLines returns the line by line difference between the text before and
+// after a change, using their longest common subsequence of lines
+func DiffLines(before, after string) []DiffLine {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ret []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ret = append(ret, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ret = append(ret, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			ret = append(ret, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ret = append(ret, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ret = append(ret, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+
+	return ret
+}
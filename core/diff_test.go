@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "undo the last commit\ngit reset HEAD~1\nkeeps the changes"
+	after := "undo the last commit\ngit reset --soft HEAD~1\nkeeps the changes\nstaged"
+
+	got := DiffLines(before, after)
+
+	expected := []DiffLine{
+		{Op: DiffEqual, Text: "undo the last commit"},
+		{Op: DiffDelete, Text: "git reset HEAD~1"},
+		{Op: DiffInsert, Text: "git reset --soft HEAD~1"},
+		{Op: DiffEqual, Text: "keeps the changes"},
+		{Op: DiffInsert, Text: "staged"},
+	}
+	testutils.AssertDeepEqual(t, got, expected, "diff mismatch")
+}
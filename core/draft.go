@@ -0,0 +1,183 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// DraftsFilename is the name of the file containing the drafts of the notes
+// being written in the editor
+const DraftsFilename = "drafts"
+
+// DefaultDraftExpiry is the number of days a draft is kept unless set in the
+// config
+var DefaultDraftExpiry = 7
+
+// Draft is the content of a note saved while it is written in the editor, so
+// that it can be recovered if the editor or the terminal is closed before the
+// note is added
+type Draft struct {
+	// BookName is the book the note is added to, or empty if the book is
+	// chosen by the rules
+	BookName string `json:"book_name"`
+	// ID tells apart the drafts whose book is chosen by the rules, since
+	// the book is only known once the note is written
+	ID      string `json:"id,omitempty"`
+	Content string `json:"content"`
+	SavedOn int64  `json:"saved_on"`
+}
+
+// rulesDraftPrefix is the prefix of the keys of the drafts whose book is
+// chosen by the rules
+var rulesDraftPrefix = "rules:"
+
+// getDraftKey returns the key of the draft in the drafts file
+func getDraftKey(d Draft) string {
+	if d.BookName == "" {
+		return rulesDraftPrefix + d.ID
+	}
+
+	return strings.ToLower(d.BookName)
+}
+
+// GetDraftsPath returns the path to the file containing the drafts
+func GetDraftsPath(ctx infra.DnoteCtx) string {
+	return fmt.Sprintf("%s/%s", ctx.DnoteDir, DraftsFilename)
+}
+
+// readDrafts returns the drafts keyed by the book name in lower case, or by
+// their ID if the book is chosen by the rules, leaving out the ones older than
+// the expiry in the config
+func readDrafts(ctx infra.DnoteCtx, config infra.DraftConfig) (map[string]Draft, error) {
+	ret := map[string]Draft{}
+
+	path := GetDraftsPath(ctx)
+	if !utils.FileExists(path) {
+		return ret, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read the drafts file")
+	}
+	var drafts map[string]Draft
+	if err := json.Unmarshal(b, &drafts); err != nil {
+		return ret, errors.Wrap(err, "Failed to unmarshal the drafts")
+	}
+
+	expiry := config.Expiry
+	if expiry <= 0 {
+		expiry = DefaultDraftExpiry
+	}
+	cutoff := time.Now().Add(-time.Duration(expiry) * 24 * time.Hour).Unix()
+
+	for _, d := range drafts {
+		if d.SavedOn >= cutoff {
+			ret[getDraftKey(d)] = d
+		}
+	}
+
+	return ret, nil
+}
+
+func writeDrafts(ctx infra.DnoteCtx, drafts map[string]Draft) error {
+	b, err := json.Marshal(drafts)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal the drafts into JSON")
+	}
+
+	if err := ioutil.WriteFile(GetDraftsPath(ctx), b, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write the drafts file")
+	}
+
+	return nil
+}
+
+// ReadDrafts returns the drafts that have not expired, most recently saved
+// first
+func ReadDrafts(ctx infra.DnoteCtx, config infra.DraftConfig) ([]Draft, error) {
+	drafts, err := readDrafts(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Draft
+	for _, d := range drafts {
+		ret = append(ret, d)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].SavedOn != ret[j].SavedOn {
+			return ret[i].SavedOn > ret[j].SavedOn
+		}
+
+		return LessBookName(ret[i].BookName, ret[j].BookName)
+	})
+
+	return ret, nil
+}
+
+// FindDraft returns the draft of the book, matching its name ignoring case.
+// If the book name is empty, it returns the most recently saved draft whose
+// book is chosen by the rules. The second return value is false if there is
+// no draft that has not expired.
+func FindDraft(ctx infra.DnoteCtx, config infra.DraftConfig, bookName string) (Draft, bool, error) {
+	if bookName != "" {
+		drafts, err := readDrafts(ctx, config)
+		if err != nil {
+			return Draft{}, false, err
+		}
+
+		d, ok := drafts[strings.ToLower(bookName)]
+		return d, ok, nil
+	}
+
+	drafts, err := ReadDrafts(ctx, config)
+	if err != nil {
+		return Draft{}, false, err
+	}
+
+	for _, d := range drafts {
+		if d.BookName == "" {
+			return d, true, nil
+		}
+	}
+
+	return Draft{}, false, nil
+}
+
+// SaveDraft saves the draft in place of the draft of the same book, or of the
+// same ID if the book is chosen by the rules, and discards the expired drafts
+func SaveDraft(ctx infra.DnoteCtx, config infra.DraftConfig, draft Draft) error {
+	drafts, err := readDrafts(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	drafts[getDraftKey(draft)] = draft
+
+	return writeDrafts(ctx, drafts)
+}
+
+// RemoveDraft discards the draft, and the expired drafts
+func RemoveDraft(ctx infra.DnoteCtx, config infra.DraftConfig, draft Draft) error {
+	if !utils.FileExists(GetDraftsPath(ctx)) {
+		return nil
+	}
+
+	drafts, err := readDrafts(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	delete(drafts, getDraftKey(draft))
+
+	return writeDrafts(ctx, drafts)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+func TestDrafts(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	config := infra.DraftConfig{Expiry: 2}
+	now := time.Now().Unix()
+
+	// Execute
+	for _, d := range []Draft{
+		{BookName: "JS", Content: "closures", SavedOn: now},
+		{BookName: "linux", Content: "grep", SavedOn: now - 3*24*60*60},
+		{BookName: "go", Content: "goroutines", SavedOn: now - 60},
+	} {
+		if err := SaveDraft(ctx, config, d); err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to save the draft"))
+		}
+	}
+	if err := RemoveDraft(ctx, config, Draft{BookName: "Go"}); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to remove the draft"))
+	}
+
+	// Test
+	drafts, err := ReadDrafts(ctx, config)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the drafts"))
+	}
+	d, ok, err := FindDraft(ctx, config, "js")
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to find the draft"))
+	}
+
+	testutils.AssertEqual(t, len(drafts), 1, "expired and removed drafts should be left out")
+	testutils.AssertEqual(t, ok, true, "draft should be found ignoring case")
+	testutils.AssertEqual(t, d.BookName, "JS", "book name mismatch")
+	testutils.AssertEqual(t, d.Content, "closures", "content mismatch")
+}
+
+func TestDrafts_Rules(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	config := infra.DraftConfig{}
+	now := time.Now().Unix()
+
+	// Execute
+	for _, d := range []Draft{
+		{ID: "d1", Content: "git stash pop", SavedOn: now - 60},
+		{ID: "d2", Content: "kubectl logs -f", SavedOn: now},
+		{BookName: "js", Content: "closures", SavedOn: now - 30},
+	} {
+		if err := SaveDraft(ctx, config, d); err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to save the draft"))
+		}
+	}
+	drafts, err := ReadDrafts(ctx, config)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the drafts"))
+	}
+	latest, ok, err := FindDraft(ctx, config, "")
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to find the draft"))
+	}
+	if err := RemoveDraft(ctx, config, latest); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to remove the draft"))
+	}
+	next, nextOK, err := FindDraft(ctx, config, "")
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to find the draft"))
+	}
+
+	// Test
+	testutils.AssertEqual(t, len(drafts), 3, "the drafts whose book is chosen by the rules should be kept apart")
+	testutils.AssertEqual(t, ok, true, "draft should be found")
+	testutils.AssertEqual(t, latest.ID, "d2", "the most recent draft should be found")
+	testutils.AssertEqual(t, nextOK, true, "the other draft should be found after the removal")
+	testutils.AssertEqual(t, next.ID, "d1", "draft mismatch")
+}
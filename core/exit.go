@@ -0,0 +1,46 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Exit codes returned by the dnote process. They are part of the interface
+// used by scripts and must not change.
+const (
+	// ExitOK means the command succeeded
+	ExitOK = 0
+	// ExitFailure means the command failed, usually due to an invalid input
+	ExitFailure = 1
+	// ExitAuthRequired means the command requires the user to login
+	ExitAuthRequired = 3
+	// ExitServerError means the server could not be reached or returned an error
+	ExitServerError = 4
+)
+
+// ExitError is an error that causes the process to exit with a specific code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// NewExitError returns an error that makes the process exit with the code
+func NewExitError(code int, err error) error {
+	return ExitError{Code: code, Err: err}
+}
+
+// GetExitCode returns the code the process should exit with for the error
+func GetExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	if e, ok := errors.Cause(err).(ExitError); ok {
+		return e.Code
+	}
+
+	return ExitFailure
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyThreshold is the minimum trigram similarity for a word to match a
+// search term in the fuzzy mode
+var fuzzyThreshold = 0.4
+
+// getTrigrams returns the set of trigrams of the word padded with spaces, so
+// that the beginning and the end of the word weigh more
+func getTrigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+
+	ret := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		ret[string(runes[i:i+3])] = true
+	}
+
+	return ret
+}
+
+// getSimilarity returns the ratio of the trigrams shared by the two words to
+// all of their trigrams, between 0 and 1
+func getSimilarity(a, b map[string]bool) float64 {
+	var shared int
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+
+	total := len(a) + len(b) - shared
+	if total == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(total)
+}
+
+// splitWords splits the text into words consisting of letters and digits
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// FuzzyMatch finds the words in the content similar to the terms of the
+// keyword, ignoring case, so that misspelled terms still match. Every term
+// must match at least one word. It returns the distinct matching words as
+// they appear in the content, or nil if the content does not match.
+func FuzzyMatch(content, keyword string) []string {
+	terms := splitWords(strings.ToLower(keyword))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	words := splitWords(content)
+	trigrams := make([]map[string]bool, len(words))
+	for i, w := range words {
+		trigrams[i] = getTrigrams(strings.ToLower(w))
+	}
+
+	var ret []string
+	seen := map[string]bool{}
+
+	for _, term := range terms {
+		termTrigrams := getTrigrams(term)
+		found := false
+
+		for i, w := range words {
+			if getSimilarity(termTrigrams, trigrams[i]) < fuzzyThreshold {
+				continue
+			}
+
+			found = true
+			if !seen[w] {
+				seen[w] = true
+				ret = append(ret, w)
+			}
+		}
+
+		if !found {
+			return nil
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	testCases := []struct {
+		content  string
+		keyword  string
+		expected []string
+	}{
+		{
+			content:  "Kubernetes pods are scheduled on nodes",
+			keyword:  "kuberentes",
+			expected: []string{"Kubernetes"},
+		},
+		{
+			content:  "Kubernetes pods are scheduled on nodes",
+			keyword:  "kubernets pod",
+			expected: []string{"Kubernetes", "pods"},
+		},
+		{
+			content:  "Kubernetes pods are scheduled on nodes",
+			keyword:  "kubernetes docker",
+			expected: nil,
+		},
+		{
+			content:  "closures capture variables",
+			keyword:  "kuberentes",
+			expected: nil,
+		},
+		{
+			content:  "closures capture variables",
+			keyword:  "",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := FuzzyMatch(tc.content, tc.keyword)
+
+		testutils.AssertDeepEqual(t, got, tc.expected, "match mismatch for "+tc.keyword)
+	}
+}
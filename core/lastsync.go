@@ -0,0 +1,117 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// LastSyncFilename is the name of the file recording the changes downloaded
+// by the last sync
+const LastSyncFilename = "last_sync"
+
+// SyncRecord is the changes downloaded by a sync
+type SyncRecord struct {
+	SyncedAt int64    `json:"synced_at"`
+	Actions  []Action `json:"actions"`
+	// Previous maps the UUIDs of the notes edited or removed by the actions to
+	// their contents before the sync
+	Previous map[string]string `json:"previous"`
+}
+
+// GetLastSyncPath returns the path to the file recording the last sync
+func GetLastSyncPath(ctx infra.DnoteCtx) string {
+	return fmt.Sprintf("%s/%s", ctx.DnoteDir, LastSyncFilename)
+}
+
+// NewSyncRecord returns the record of the actions downloaded by a sync. It
+// must be called before the actions are reduced so that the contents of the
+// notes before the sync are recorded.
+func NewSyncRecord(ctx infra.DnoteCtx, actions []Action, syncedAt int64) (SyncRecord, error) {
+	ret := SyncRecord{
+		SyncedAt: syncedAt,
+		Actions:  actions,
+		Previous: map[string]string{},
+	}
+
+	dnote, err := GetDnote(ctx)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read dnote")
+	}
+
+	contents := map[string]string{}
+	for _, book := range dnote {
+		for _, note := range book.Notes {
+			contents[note.UUID] = note.Content
+		}
+	}
+
+	for _, action := range actions {
+		var noteUUID string
+
+		switch action.Type {
+		case ActionEditNote:
+			var data EditNoteData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+			noteUUID = data.NoteUUID
+		case ActionRemoveNote:
+			var data RemoveNoteData
+			if err := json.Unmarshal(action.Data, &data); err != nil {
+				return ret, errors.Wrap(err, "Failed to parse the action data")
+			}
+			noteUUID = data.NoteUUID
+		default:
+			continue
+		}
+
+		if content, ok := contents[noteUUID]; ok {
+			if _, recorded := ret.Previous[noteUUID]; !recorded {
+				ret.Previous[noteUUID] = content
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// ReadLastSync returns the record of the last sync. It returns a zero record
+// if the CLI has not synced since the record was introduced.
+func ReadLastSync(ctx infra.DnoteCtx) (SyncRecord, error) {
+	var ret SyncRecord
+
+	path := GetLastSyncPath(ctx)
+	if !utils.FileExists(path) {
+		return ret, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read the last sync file")
+	}
+
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return ret, errors.Wrap(err, "Failed to unmarshal the last sync")
+	}
+
+	return ret, nil
+}
+
+// WriteLastSync writes the record of the last sync
+func WriteLastSync(ctx infra.DnoteCtx, record SyncRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal the last sync into JSON")
+	}
+
+	if err := ioutil.WriteFile(GetLastSyncPath(ctx), b, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write the last sync file")
+	}
+
+	return nil
+}
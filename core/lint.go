@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LinterSpell reports the words not found in the dictionary
+	LinterSpell = "spell"
+	// LinterURL reports the links that are broken
+	LinterURL = "url"
+	// LinterTODO reports the TODO and FIXME markers
+	LinterTODO = "todo"
+)
+
+var (
+	todoPattern = regexp.MustCompile(`\b(TODO|FIXME|XXX)\b`)
+	urlPattern  = regexp.MustCompile(`https?://[^\s<>"'()]+`)
+)
+
+// spellArgs are the arguments making each supported spell checker print the
+// misspelled words in its input, one per line
+var spellArgs = map[string][]string{
+	"aspell":   {"list"},
+	"hunspell": {"-l"},
+}
+
+// urlTimeout is how long the url linter waits for a link to respond
+var urlTimeout = 5 * time.Second
+
+// LintWarning is a problem found in a note by a linter
+type LintWarning struct {
+	Linter  string
+	Message string
+}
+
+// LintContent runs the linters in the config on the content of a note
+func LintContent(config infra.LintConfig, content string) ([]LintWarning, error) {
+	var ret []LintWarning
+
+	for _, linter := range config.Linters {
+		var warnings []LintWarning
+		var err error
+
+		switch linter {
+		case LinterSpell:
+			warnings, err = lintSpelling(config, content)
+		case LinterURL:
+			warnings = lintURLs(content)
+		case LinterTODO:
+			warnings = lintTODOs(content)
+		default:
+			return ret, errors.Errorf("Unknown linter %s", linter)
+		}
+		if err != nil {
+			return ret, errors.Wrapf(err, "Failed to run the linter %s", linter)
+		}
+
+		ret = append(ret, warnings...)
+	}
+
+	return ret, nil
+}
+
+func lintTODOs(content string) []LintWarning {
+	var ret []LintWarning
+
+	for _, m := range todoPattern.FindAllString(content, -1) {
+		ret = append(ret, LintWarning{Linter: LinterTODO, Message: fmt.Sprintf("contains %s", m)})
+	}
+
+	return ret
+}
+
+func lintURLs(content string) []LintWarning {
+	var ret []LintWarning
+
+	client := http.Client{Timeout: urlTimeout}
+	seen := map[string]bool{}
+
+	for _, u := range urlPattern.FindAllString(content, -1) {
+		u = strings.TrimRight(u, ".,;:!?")
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+
+		resp, err := client.Head(u)
+		if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+			resp.Body.Close()
+			resp, err = client.Get(u)
+		}
+		if err != nil {
+			ret = append(ret, LintWarning{Linter: LinterURL, Message: fmt.Sprintf("%s is unreachable", u)})
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			ret = append(ret, LintWarning{Linter: LinterURL, Message: fmt.Sprintf("%s responded with %s", u, resp.Status)})
+		}
+	}
+
+	return ret
+}
+
+func lintSpelling(config infra.LintConfig, content string) ([]LintWarning, error) {
+	name := config.SpellCommand
+	if name == "" {
+		name = "aspell"
+	}
+
+	args, ok := spellArgs[name]
+	if !ok {
+		return nil, errors.Errorf("Unsupported spell checker %s. Use aspell or hunspell", name)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "Failed to run %s: %s", name, stderr.String())
+	}
+
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range strings.Fields(stdout.String()) {
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(words)
+
+	return []LintWarning{{Linter: LinterSpell, Message: fmt.Sprintf("possible misspellings: %s", strings.Join(words, ", "))}}, nil
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestLintContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := infra.LintConfig{Linters: []string{LinterTODO, LinterURL}}
+	content := "TODO: read " + server.URL + "/ok and " + server.URL + "/gone."
+
+	warnings, err := LintContent(config, content)
+	if err != nil {
+		t.Fatalf("Failed to lint: %s", err.Error())
+	}
+
+	testutils.AssertEqual(t, len(warnings), 2, "warning count mismatch")
+	testutils.AssertEqual(t, warnings[0].Linter, LinterTODO, "linter mismatch")
+	testutils.AssertEqual(t, warnings[0].Message, "contains TODO", "message mismatch")
+	testutils.AssertEqual(t, warnings[1].Linter, LinterURL, "linter mismatch")
+	testutils.AssertEqual(t, warnings[1].Message, server.URL+"/gone responded with 404 Not Found", "message mismatch")
+
+	t.Run("unknown linter", func(t *testing.T) {
+		_, err := LintContent(infra.LintConfig{Linters: []string{"grammar"}}, content)
+		if err == nil {
+			t.Fatal("Expected an error for the unknown linter")
+		}
+	})
+}
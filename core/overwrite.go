@@ -0,0 +1,124 @@
+package core
+
+import (
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// GetChangeActions returns the actions that turn the notes in from into the
+// notes in to. Notes are matched by UUID within the same book.
+func GetChangeActions(from, to infra.Dnote, ts int64) ([]Action, error) {
+	var actions []Action
+
+	var fromNames []string
+	for name := range from {
+		fromNames = append(fromNames, name)
+	}
+	SortBookNames(fromNames)
+
+	for _, name := range fromNames {
+		if _, ok := to[name]; ok {
+			continue
+		}
+
+		action, err := NewActionRemoveBook(name, ts)
+		if err != nil {
+			return actions, errors.Wrap(err, "Failed to make the action")
+		}
+		actions = append(actions, action)
+	}
+
+	var toNames []string
+	for name := range to {
+		toNames = append(toNames, name)
+	}
+	SortBookNames(toNames)
+
+	for _, name := range toNames {
+		book := to[name]
+		fromBook, exists := from[name]
+
+		if !exists {
+			action, err := NewActionAddBook(name, ts)
+			if err != nil {
+				return actions, errors.Wrap(err, "Failed to make the action")
+			}
+			actions = append(actions, action)
+		}
+
+		fromNotes := map[string]infra.Note{}
+		for _, note := range fromBook.Notes {
+			fromNotes[note.UUID] = note
+		}
+
+		kept := map[string]bool{}
+		for _, note := range book.Notes {
+			fromNote, ok := fromNotes[note.UUID]
+
+			var action Action
+			var err error
+			switch {
+			case !ok:
+				action, err = NewActionAddNote(note.UUID, name, note.Content, ts)
+			case fromNote.Content != note.Content:
+				action, err = NewActionEditNote(note.UUID, name, note.Content, ts)
+			}
+			if err != nil {
+				return actions, errors.Wrap(err, "Failed to make the action")
+			}
+			if action.Type != "" {
+				actions = append(actions, action)
+			}
+
+			kept[note.UUID] = true
+		}
+
+		for _, note := range fromBook.Notes {
+			if kept[note.UUID] {
+				continue
+			}
+
+			action, err := NewActionRemoveNote(note.UUID, name, ts)
+			if err != nil {
+				return actions, errors.Wrap(err, "Failed to make the action")
+			}
+			actions = append(actions, action)
+		}
+	}
+
+	return actions, nil
+}
+
+// GetOverwriteActions returns the actions that turn the server state into the
+// local state, so that the local state overwrites the server when they are
+// synced. A local note that is not in the same book on the server is given a
+// new UUID, because the server may have kept its removal and would not add it
+// back. The local state with the new UUIDs is returned along with the actions.
+func GetOverwriteActions(server, local infra.Dnote, ts int64) (infra.Dnote, []Action, error) {
+	ret := infra.Dnote{}
+	for name, book := range local {
+		onServer := map[string]bool{}
+		for _, note := range server[name].Notes {
+			onServer[note.UUID] = true
+		}
+
+		notes := make([]infra.Note, 0, len(book.Notes))
+		for _, note := range book.Notes {
+			if !onServer[note.UUID] {
+				note.UUID = utils.GenerateUID()
+			}
+
+			notes = append(notes, note)
+		}
+
+		ret[name] = GetUpdatedBook(book, notes)
+	}
+
+	actions, err := GetChangeActions(server, ret, ts)
+	if err != nil {
+		return local, actions, errors.Wrap(err, "Failed to get the changes")
+	}
+
+	return ret, actions, nil
+}
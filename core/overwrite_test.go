@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/pkg/errors"
+)
+
+func TestGetOverwriteActions(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	server := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "closures"},
+			{UUID: "n2", Content: "hoisting"},
+			{UUID: "n3", Content: "promises"},
+		}},
+		"linux": infra.Book{Name: "linux", Notes: []infra.Note{
+			{UUID: "n4", Content: "grep"},
+		}},
+	}
+	local := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "closures"},
+			{UUID: "n2", Content: "hoisting is edited"},
+			{UUID: "n5", Content: "generators", Priority: PriorityHigh},
+		}},
+		"go": infra.Book{Name: "go", Notes: []infra.Note{
+			{UUID: "n4", Content: "goroutines"},
+		}},
+	}
+	if err := WriteDnote(ctx, server); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to write dnote"))
+	}
+
+	// Execute
+	updated, actions, err := GetOverwriteActions(server, local, 1517629805)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get the actions"))
+	}
+	if err := ReduceAll(ctx, actions); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to reduce the actions"))
+	}
+
+	// Test
+	dnote, err := GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	testutils.AssertEqual(t, len(actions), 6, "actions length mismatch")
+	testutils.AssertEqual(t, len(dnote), 2, "book count mismatch")
+	testutils.AssertEqual(t, updated["js"].Notes[0].UUID, "n1", "unchanged note uuid mismatch")
+	testutils.AssertEqual(t, updated["js"].Notes[1].UUID, "n2", "edited note uuid mismatch")
+	testutils.AssertNotEqual(t, updated["js"].Notes[2].UUID, "n5", "note not on the server should get a new uuid")
+	testutils.AssertEqual(t, updated["js"].Notes[2].Priority, PriorityHigh, "local priority should be kept")
+	testutils.AssertNotEqual(t, updated["go"].Notes[0].UUID, "n4", "note in another book on the server should get a new uuid")
+
+	for _, name := range []string{"js", "go"} {
+		testutils.AssertEqual(t, len(dnote[name].Notes), len(updated[name].Notes), name+" notes length mismatch")
+
+		for i, note := range updated[name].Notes {
+			testutils.AssertEqual(t, dnote[name].Notes[i].UUID, note.UUID, name+" note uuid mismatch")
+			testutils.AssertEqual(t, dnote[name].Notes[i].Content, note.Content, name+" note content mismatch")
+		}
+	}
+}
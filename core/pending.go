@@ -0,0 +1,68 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// PendingFilename is the name of the file holding the actions downloaded from
+// the server that have not been applied yet, because the sync that downloaded
+// them was aborted or failed to apply them
+const PendingFilename = "pending"
+
+// GetPendingPath returns the path to the file holding the pending actions
+func GetPendingPath(ctx infra.DnoteCtx) string {
+	return fmt.Sprintf("%s/%s", ctx.DnoteDir, PendingFilename)
+}
+
+// ReadPendingActions returns the actions downloaded from the server that have
+// not been applied yet
+func ReadPendingActions(ctx infra.DnoteCtx) ([]Action, error) {
+	var ret []Action
+
+	path := GetPendingPath(ctx)
+	if !utils.FileExists(path) {
+		return ret, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read the pending actions file")
+	}
+
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return ret, errors.Wrap(err, "Failed to unmarshal the pending actions")
+	}
+
+	return ret, nil
+}
+
+// WritePendingActions writes the actions downloaded from the server so that
+// they are applied by the next sync
+func WritePendingActions(ctx infra.DnoteCtx, actions []Action) error {
+	b, err := json.Marshal(actions)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal the pending actions into JSON")
+	}
+
+	if err := ioutil.WriteFile(GetPendingPath(ctx), b, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write the pending actions file")
+	}
+
+	return nil
+}
+
+// ClearPendingActions removes the pending actions once they are applied
+func ClearPendingActions(ctx infra.DnoteCtx) error {
+	if err := os.Remove(GetPendingPath(ctx)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Failed to remove the pending actions file")
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	// PriorityNone is the priority of the notes without a label
+	PriorityNone = ""
+	PriorityLow  = "low"
+	PriorityMed  = "med"
+	PriorityHigh = "high"
+)
+
+// ParsePriority parses the name of a priority, ignoring case. "none" is the
+// name of PriorityNone.
+func ParsePriority(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return PriorityNone, nil
+	case PriorityLow:
+		return PriorityLow, nil
+	case PriorityMed, "medium":
+		return PriorityMed, nil
+	case PriorityHigh:
+		return PriorityHigh, nil
+	}
+
+	return "", errors.Errorf("Unknown priority %s. Use none, low, med, or high", s)
+}
+
+// priorityColors are the colors of the markers of the priorities
+var priorityColors = map[string]int{
+	PriorityLow:  log.ColorBlue,
+	PriorityMed:  log.ColorYellow,
+	PriorityHigh: log.ColorRed,
+}
+
+// PriorityMarker returns the colored marker printed before the title of a note
+// with the priority, or an empty string for PriorityNone
+func PriorityMarker(priority string) string {
+	color, ok := priorityColors[priority]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("\033[%dm●\033[0m ", color)
+}
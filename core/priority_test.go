@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestParsePriority(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{input: "none", expected: PriorityNone, valid: true},
+		{input: "low", expected: PriorityLow, valid: true},
+		{input: "Med", expected: PriorityMed, valid: true},
+		{input: "medium", expected: PriorityMed, valid: true},
+		{input: "HIGH", expected: PriorityHigh, valid: true},
+		{input: "", valid: false},
+		{input: "urgent", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			priority, err := ParsePriority(tc.input)
+
+			testutils.AssertEqual(t, err == nil, tc.valid, "validity mismatch")
+			testutils.AssertEqual(t, priority, tc.expected, "priority mismatch")
+		})
+	}
+}
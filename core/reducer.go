@@ -81,6 +81,7 @@ func handleAddNote(ctx infra.DnoteCtx, action Action) error {
 	note := infra.Note{
 		UUID:    data.NoteUUID,
 		Content: data.Content,
+		Title:   GetTitle(data.Content),
 		AddedOn: action.Timestamp,
 	}
 
@@ -164,6 +165,7 @@ func handleEditNote(ctx infra.DnoteCtx, action Action) error {
 	for idx, note := range book.Notes {
 		if note.UUID == data.NoteUUID {
 			note.Content = data.Content
+			note.Title = GetTitle(data.Content)
 			note.EditedOn = action.Timestamp
 			dnote[book.Name].Notes[idx] = note
 		}
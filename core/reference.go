@@ -0,0 +1,131 @@
+package core
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// TrackerJira is the tracker of references like PROJ-123
+	TrackerJira = "jira"
+	// TrackerGitHub is the tracker of references like org/repo#45
+	TrackerGitHub = "github"
+)
+
+var (
+	// Jira project keys have at least two letters
+	jiraPattern   = regexp.MustCompile(`\b([A-Z][A-Z0-9]*[A-Z][A-Z0-9]*)-([0-9]+)\b`)
+	githubPattern = regexp.MustCompile(`\b([A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+)#([0-9]+)\b`)
+)
+
+// nonJiraProjects are the prefixes of the names of standards and algorithms,
+// such as UTF-8 and SHA-256, which look like Jira references but are not
+var nonJiraProjects = map[string]bool{
+	"AES":  true,
+	"ECMA": true,
+	"IEC":  true,
+	"ISO":  true,
+	"RFC":  true,
+	"RSA":  true,
+	"SHA":  true,
+	"UCS":  true,
+	"UTF":  true,
+}
+
+// defaultTrackerURLs are the URL templates used for the trackers that are not
+// configured
+var defaultTrackerURLs = map[string]string{
+	TrackerGitHub: "https://github.com/{project}/issues/{number}",
+}
+
+// Reference is a reference to an issue in an external tracker
+type Reference struct {
+	Tracker string
+	// Project is the project key for Jira and the repository for GitHub
+	Project string
+	Number  string
+}
+
+// String returns the reference as written in notes
+func (r Reference) String() string {
+	if r.Tracker == TrackerGitHub {
+		return r.Project + "#" + r.Number
+	}
+
+	return r.Project + "-" + r.Number
+}
+
+// URL returns the link to the issue using the URL template for the tracker.
+// Templates can contain {ref}, {project}, and {number}. It returns an empty
+// string if no template is available.
+func (r Reference) URL(templates map[string]string) string {
+	tmpl, ok := templates[r.Tracker]
+	if !ok {
+		tmpl = defaultTrackerURLs[r.Tracker]
+	}
+	if tmpl == "" {
+		return ""
+	}
+
+	replacer := strings.NewReplacer("{ref}", r.String(), "{project}", r.Project, "{number}", r.Number)
+	return replacer.Replace(tmpl)
+}
+
+// GetReferences returns the issue references in the content, in the order
+// they appear, without duplicates
+func GetReferences(content string) []Reference {
+	type found struct {
+		pos int
+		ref Reference
+	}
+	var all []found
+
+	patterns := map[string]*regexp.Regexp{
+		TrackerGitHub: githubPattern,
+		TrackerJira:   jiraPattern,
+	}
+	for tracker, pattern := range patterns {
+		for _, m := range pattern.FindAllStringSubmatchIndex(content, -1) {
+			ref := Reference{
+				Tracker: tracker,
+				Project: content[m[2]:m[3]],
+				Number:  content[m[4]:m[5]],
+			}
+			if tracker == TrackerJira && nonJiraProjects[ref.Project] {
+				continue
+			}
+
+			all = append(all, found{pos: m[0], ref: ref})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].pos < all[j].pos
+	})
+
+	var ret []Reference
+	seen := map[Reference]bool{}
+
+	for _, f := range all {
+		if seen[f.ref] {
+			continue
+		}
+
+		seen[f.ref] = true
+		ret = append(ret, f.ref)
+	}
+
+	return ret
+}
+
+// HasReference checks if the content references the issue, ignoring case
+func HasReference(content, ref string) bool {
+	for _, r := range GetReferences(content) {
+		if strings.EqualFold(r.String(), ref) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestGetReferences(t *testing.T) {
+	content := "fixed in dnote-io/cli#45 and tracked as WEB-123. see WEB-123 and AB2-7"
+
+	refs := GetReferences(content)
+
+	testutils.AssertEqual(t, len(refs), 3, "reference count mismatch")
+	testutils.AssertEqual(t, refs[0].Tracker, TrackerGitHub, "tracker mismatch")
+	testutils.AssertEqual(t, refs[0].Project, "dnote-io/cli", "project mismatch")
+	testutils.AssertEqual(t, refs[0].Number, "45", "number mismatch")
+	testutils.AssertEqual(t, refs[1].String(), "WEB-123", "reference mismatch")
+	testutils.AssertEqual(t, refs[2].String(), "AB2-7", "reference mismatch")
+}
+
+func TestGetReferences_NotJira(t *testing.T) {
+	testCases := []string{
+		"encoded in UTF-8",
+		"hashed with SHA-256",
+		"dates in ISO-8601",
+		"see X-1",
+		"see X1-2",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			testutils.AssertEqual(t, len(GetReferences(tc)), 0, "reference count mismatch")
+		})
+	}
+}
+
+func TestReferenceURL(t *testing.T) {
+	templates := map[string]string{
+		TrackerJira: "https://example.atlassian.net/browse/{ref}",
+	}
+
+	jira := Reference{Tracker: TrackerJira, Project: "WEB", Number: "123"}
+	github := Reference{Tracker: TrackerGitHub, Project: "dnote-io/cli", Number: "45"}
+
+	testutils.AssertEqual(t, jira.URL(templates), "https://example.atlassian.net/browse/WEB-123", "jira URL mismatch")
+	testutils.AssertEqual(t, github.URL(templates), "https://github.com/dnote-io/cli/issues/45", "github URL mismatch")
+	testutils.AssertEqual(t, jira.URL(map[string]string{}), "", "jira URL should be empty without a template")
+}
+
+func TestHasReference(t *testing.T) {
+	testutils.AssertEqual(t, HasReference("tracked as WEB-123", "web-123"), true, "reference should match ignoring case")
+	testutils.AssertEqual(t, HasReference("tracked as WEB-1234", "WEB-123"), false, "reference should not match a prefix")
+}
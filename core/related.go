@@ -0,0 +1,147 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dnote-io/cli/infra"
+)
+
+// stopWords are the common English words left out when comparing notes,
+// because they appear in most notes and say little about their subject
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "for": true, "from": true, "how": true,
+	"if": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "use": true,
+	"with": true, "you": true,
+}
+
+// RelatedNote is a note similar to another note
+type RelatedNote struct {
+	BookName string
+	Index    int
+	Note     infra.Note
+	// Score is the cosine similarity of the term vectors of the notes, between
+	// 0 and 1
+	Score float64
+}
+
+// noteRef identifies a note by its book and its index in the book
+type noteRef struct {
+	BookName string
+	Index    int
+}
+
+// getTermCounts returns the number of times each term appears in the content,
+// ignoring case and leaving out the stop words and the single characters
+func getTermCounts(content string) map[string]int {
+	ret := map[string]int{}
+
+	for _, w := range splitWords(strings.ToLower(content)) {
+		if utf8.RuneCountInString(w) < 2 || stopWords[w] {
+			continue
+		}
+
+		ret[w]++
+	}
+
+	return ret
+}
+
+// getTermVectors returns the TF-IDF vectors of the terms of the notes, so that
+// the terms that appear in fewer notes weigh more
+func getTermVectors(counts map[noteRef]map[string]int) map[noteRef]map[string]float64 {
+	docFreq := map[string]int{}
+	for _, c := range counts {
+		for term := range c {
+			docFreq[term]++
+		}
+	}
+
+	n := float64(len(counts))
+	ret := map[noteRef]map[string]float64{}
+	for ref, c := range counts {
+		v := map[string]float64{}
+		for term, count := range c {
+			v[term] = float64(count) * math.Log(1+n/float64(docFreq[term]))
+		}
+
+		ret[ref] = v
+	}
+
+	return ret
+}
+
+// getCosine returns the cosine similarity of the two vectors
+func getCosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, x := range a {
+		normA += x * x
+		if y, ok := b[term]; ok {
+			dot += x * y
+		}
+	}
+	for _, y := range b {
+		normB += y * y
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// FindRelatedNotes returns at most limit notes most similar to the note at the
+// index in the book, most similar first. The notes that share no term with it
+// are left out.
+func FindRelatedNotes(dnote infra.Dnote, bookName string, index int, limit int) []RelatedNote {
+	counts := map[noteRef]map[string]int{}
+	for name, book := range dnote {
+		for i, note := range book.Notes {
+			counts[noteRef{BookName: name, Index: i}] = getTermCounts(note.Content)
+		}
+	}
+
+	vectors := getTermVectors(counts)
+	target := noteRef{BookName: bookName, Index: index}
+
+	var ret []RelatedNote
+	for ref, v := range vectors {
+		if ref == target {
+			continue
+		}
+
+		score := getCosine(vectors[target], v)
+		if score == 0 {
+			continue
+		}
+
+		ret = append(ret, RelatedNote{
+			BookName: ref.BookName,
+			Index:    ref.Index,
+			Note:     dnote[ref.BookName].Notes[ref.Index],
+			Score:    score,
+		})
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Score != ret[j].Score {
+			return ret[i].Score > ret[j].Score
+		}
+		if ret[i].BookName != ret[j].BookName {
+			return LessBookName(ret[i].BookName, ret[j].BookName)
+		}
+
+		return ret[i].Index < ret[j].Index
+	})
+
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+
+	return ret
+}
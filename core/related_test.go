@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestFindRelatedNotes(t *testing.T) {
+	dnote := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "Closures capture the variables of the enclosing scope"},
+			{UUID: "n2", Content: "Promises chain with then"},
+		}},
+		"go": infra.Book{Name: "go", Notes: []infra.Note{
+			{UUID: "n3", Content: "Closures in Go capture variables by reference"},
+			{UUID: "n4", Content: "Goroutines are cheap"},
+			{UUID: "n5", Content: "The scope of a variable declared in a loop"},
+		}},
+	}
+
+	related := FindRelatedNotes(dnote, "js", 0, 5)
+
+	testutils.AssertEqual(t, len(related), 2, "notes sharing no term should be left out")
+	testutils.AssertEqual(t, related[0].Note.UUID, "n3", "most related note mismatch")
+	testutils.AssertEqual(t, related[1].Note.UUID, "n5", "second related note mismatch")
+	testutils.AssertEqual(t, related[0].Score > related[1].Score, true, "notes should be sorted by score")
+
+	limited := FindRelatedNotes(dnote, "js", 0, 1)
+	testutils.AssertEqual(t, len(limited), 1, "limit mismatch")
+}
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"regexp"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/pkg/errors"
+)
+
+// MatchRule returns the first rule whose pattern matches the content. The
+// boolean is false if no rule matches.
+func MatchRule(rules []infra.Rule, content string) (infra.Rule, bool, error) {
+	for i, rule := range rules {
+		if rule.Book == "" {
+			return infra.Rule{}, false, errors.Errorf("Rule #%d is missing a book", i+1)
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return infra.Rule{}, false, errors.Wrapf(err, "Invalid pattern in rule #%d", i+1)
+		}
+
+		if re.MatchString(content) {
+			return rule, true, nil
+		}
+	}
+
+	return infra.Rule{}, false, nil
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+)
+
+func TestMatchRule(t *testing.T) {
+	rules := []infra.Rule{
+		{Pattern: `(?i)\bkubectl\b`, Book: "k8s"},
+		{Pattern: `^git `, Book: "git"},
+		{Pattern: `git`, Book: "misc"},
+	}
+
+	testCases := []struct {
+		content  string
+		expected string
+		matched  bool
+	}{
+		{
+			content:  "Kubectl get pods -w watches the pods",
+			expected: "k8s",
+			matched:  true,
+		},
+		{
+			content:  "git log -p shows the patches",
+			expected: "git",
+			matched:  true,
+		},
+		{
+			content:  "use git bisect to find the bad commit",
+			expected: "misc",
+			matched:  true,
+		},
+		{
+			content:  "closures capture variables",
+			expected: "",
+			matched:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		rule, ok, err := MatchRule(rules, tc.content)
+		if err != nil {
+			t.Fatalf("Failed to match rules for %s: %s", tc.content, err.Error())
+		}
+
+		testutils.AssertEqual(t, ok, tc.matched, "matched mismatch for "+tc.content)
+		testutils.AssertEqual(t, rule.Book, tc.expected, "book mismatch for "+tc.content)
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, _, err := MatchRule([]infra.Rule{{Pattern: "(", Book: "js"}}, "foo")
+		if err == nil {
+			t.Fatal("Expected an error for the invalid pattern")
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+// SnapshotDirName is the name of the directory containing the snapshots of
+// the local data taken before each sync
+const SnapshotDirName = "snapshots"
+
+// DefaultSnapshotCount is the number of snapshots kept unless set in the config
+var DefaultSnapshotCount = 5
+
+// snapshotFiles are the files changed by a sync, which are saved in snapshots.
+// The schema is saved along with them so that restoring a snapshot taken before
+// a migration runs the migration again.
+var snapshotFiles = []string{DnoteFilename, ActionFilename, TimestampFilename, LastSyncFilename, PendingFilename, SchemaFilename}
+
+// uploadedFilename is the name of the file marking a snapshot taken before a
+// sync whose local changes were accepted by the server
+const uploadedFilename = "uploaded"
+
+// Snapshot is a copy of the local data taken before a sync
+type Snapshot struct {
+	// Name is the name of the directory of the snapshot, which is the time it
+	// was taken in unix nanoseconds
+	Name      string
+	CreatedAt time.Time
+	// Size is the total size of the files in bytes
+	Size int64
+	// Uploaded is true if the server accepted the local changes in the sync
+	// that followed the snapshot
+	Uploaded bool
+}
+
+// GetSnapshotDir returns the path to the directory containing the snapshots
+func GetSnapshotDir(ctx infra.DnoteCtx) string {
+	return fmt.Sprintf("%s/%s", ctx.DnoteDir, SnapshotDirName)
+}
+
+// TakeSnapshot copies the local data into a new snapshot, and removes the
+// oldest snapshots beyond the count or the size in the config
+func TakeSnapshot(ctx infra.DnoteCtx, config infra.SnapshotConfig) (Snapshot, error) {
+	now := time.Now()
+	ret := Snapshot{Name: strconv.FormatInt(now.UnixNano(), 10), CreatedAt: now}
+	dir := filepath.Join(GetSnapshotDir(ctx), ret.Name)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ret, errors.Wrap(err, "Failed to create the snapshot directory")
+	}
+
+	for _, filename := range snapshotFiles {
+		src := filepath.Join(ctx.DnoteDir, filename)
+		if !utils.FileExists(src) {
+			continue
+		}
+
+		if err := utils.CopyFile(src, filepath.Join(dir, filename)); err != nil {
+			return ret, errors.Wrapf(err, "Failed to copy %s", filename)
+		}
+	}
+
+	if err := pruneSnapshots(ctx, config); err != nil {
+		return ret, errors.Wrap(err, "Failed to remove old snapshots")
+	}
+
+	return ret, nil
+}
+
+// MarkSnapshotUploaded records that the server accepted the local changes in
+// the sync that followed the snapshot
+func MarkSnapshotUploaded(ctx infra.DnoteCtx, snapshot Snapshot) error {
+	path := filepath.Join(GetSnapshotDir(ctx), snapshot.Name, uploadedFilename)
+
+	if err := ioutil.WriteFile(path, []byte{}, 0644); err != nil {
+		return errors.Wrap(err, "Failed to mark the snapshot")
+	}
+
+	return nil
+}
+
+// HasUploadedChanges checks if the local changes in the action log of the
+// snapshot have been accepted by the server since it was taken. Restoring such
+// a snapshot would bring back notes the server has already received along
+// with the bookmark before them, and the next sync would receive them again.
+func HasUploadedChanges(ctx infra.DnoteCtx, snapshot Snapshot) (bool, error) {
+	path := filepath.Join(GetSnapshotDir(ctx), snapshot.Name, ActionFilename)
+	if !utils.FileExists(path) {
+		return false, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to read the action log of the snapshot")
+	}
+
+	var actions []Action
+	if err := json.Unmarshal(b, &actions); err != nil {
+		return false, errors.Wrap(err, "Failed to unmarshal the action log of the snapshot")
+	}
+	if len(actions) == 0 {
+		return false, nil
+	}
+
+	snapshots, err := ListSnapshots(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to list the snapshots")
+	}
+
+	// The changes stay in the action log until a sync is accepted, so they are
+	// uploaded by the first accepted sync after the snapshot
+	for _, s := range snapshots {
+		if s.CreatedAt.Before(snapshot.CreatedAt) {
+			break
+		}
+		if s.Uploaded {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// pruneSnapshots removes the oldest snapshots until the remaining ones are
+// within the count and the size in the config. The newest one is always kept.
+func pruneSnapshots(ctx infra.DnoteCtx, config infra.SnapshotConfig) error {
+	count := config.Count
+	if count <= 0 {
+		count = DefaultSnapshotCount
+	}
+
+	snapshots, err := ListSnapshots(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to list the snapshots")
+	}
+
+	var total int64
+	for i, s := range snapshots {
+		total += s.Size
+
+		if i == 0 {
+			continue
+		}
+		if i < count && (config.MaxSize <= 0 || total <= config.MaxSize) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(GetSnapshotDir(ctx), s.Name)); err != nil {
+			return errors.Wrapf(err, "Failed to remove the snapshot %s", s.Name)
+		}
+	}
+
+	return nil
+}
+
+// ListSnapshots returns the snapshots, newest first
+func ListSnapshots(ctx infra.DnoteCtx) ([]Snapshot, error) {
+	var ret []Snapshot
+
+	dir := GetSnapshotDir(ctx)
+	if !utils.FileExists(dir) {
+		return ret, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read the snapshot directory")
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		nsec, err := strconv.ParseInt(entry.Name(), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return ret, errors.Wrapf(err, "Failed to read the snapshot %s", entry.Name())
+		}
+
+		s := Snapshot{Name: entry.Name(), CreatedAt: time.Unix(0, nsec)}
+		for _, f := range files {
+			if f.Name() == uploadedFilename {
+				s.Uploaded = true
+				continue
+			}
+
+			s.Size += f.Size()
+		}
+
+		ret = append(ret, s)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].CreatedAt.After(ret[j].CreatedAt)
+	})
+
+	return ret, nil
+}
+
+// RestoreSnapshot replaces the local data with the copy in the snapshot. The
+// files that did not exist when the snapshot was taken are removed.
+func RestoreSnapshot(ctx infra.DnoteCtx, snapshot Snapshot) error {
+	dir := filepath.Join(GetSnapshotDir(ctx), snapshot.Name)
+
+	for _, filename := range snapshotFiles {
+		src := filepath.Join(dir, filename)
+		dest := filepath.Join(ctx.DnoteDir, filename)
+
+		if !utils.FileExists(src) {
+			if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "Failed to remove %s", filename)
+			}
+
+			continue
+		}
+
+		if err := utils.CopyFile(src, dest); err != nil {
+			return errors.Wrapf(err, "Failed to restore %s", filename)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/testutils"
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+	testutils.WriteFile(ctx, "../testutils/fixtures/dnote1.json", "dnote")
+
+	// Execute
+	for i := 0; i < 3; i++ {
+		if _, err := TakeSnapshot(ctx, infra.SnapshotConfig{Count: 2}); err != nil {
+			t.Fatal(errors.Wrap(err, "Failed to take a snapshot"))
+		}
+	}
+
+	// Test
+	snapshots, err := ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to list snapshots"))
+	}
+
+	testutils.AssertEqual(t, len(snapshots), 2, "snapshot count mismatch")
+	if !snapshots[0].CreatedAt.After(snapshots[1].CreatedAt) {
+		t.Error("snapshots should be ordered newest first")
+	}
+	testutils.AssertEqual(t, utils.FileExists(filepath.Join(GetSnapshotDir(ctx), snapshots[0].Name, DnoteFilename)), true, "dnote should be in the snapshot")
+	testutils.AssertEqual(t, utils.FileExists(filepath.Join(GetSnapshotDir(ctx), snapshots[0].Name, LastSyncFilename)), false, "missing files should not be in the snapshot")
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("../tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+	testutils.WriteFile(ctx, "../testutils/fixtures/dnote1.json", "dnote")
+	if err := ioutil.WriteFile(filepath.Join(ctx.DnoteDir, SchemaFilename), []byte("current_version: 5\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the schema"))
+	}
+
+	if _, err := TakeSnapshot(ctx, infra.SnapshotConfig{}); err != nil {
+		panic(errors.Wrap(err, "Failed to take a snapshot"))
+	}
+	testutils.WriteFile(ctx, "../testutils/fixtures/dnote3.json", "dnote")
+	if err := ioutil.WriteFile(filepath.Join(ctx.DnoteDir, SchemaFilename), []byte("current_version: 6\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the schema"))
+	}
+	if err := WriteLastSync(ctx, SyncRecord{SyncedAt: 1}); err != nil {
+		panic(errors.Wrap(err, "Failed to write the last sync"))
+	}
+
+	snapshots, err := ListSnapshots(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to list snapshots"))
+	}
+
+	// Execute
+	if err := RestoreSnapshot(ctx, snapshots[0]); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to restore the snapshot"))
+	}
+
+	// Test
+	dnote, err := GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	testutils.AssertEqual(t, len(dnote), 1, "There should be 1 book")
+	testutils.AssertEqual(t, utils.FileExists(GetLastSyncPath(ctx)), false, "last sync should be removed")
+	testutils.AssertEqual(t, string(testutils.ReadFile(ctx, SchemaFilename)), "current_version: 5\n", "schema should be restored")
+}
+
+func TestHasUploadedChanges(t *testing.T) {
+	testCases := []struct {
+		name           string
+		actions        int
+		uploadedOldest bool
+		uploadedNewest bool
+		expected       bool
+	}{
+		{
+			name:     "not synced since",
+			actions:  1,
+			expected: false,
+		},
+		{
+			name:           "synced right after",
+			actions:        1,
+			uploadedOldest: true,
+			expected:       true,
+		},
+		{
+			name:           "synced by a later sync",
+			actions:        1,
+			uploadedNewest: true,
+			expected:       true,
+		},
+		{
+			name:           "no local changes",
+			actions:        0,
+			uploadedOldest: true,
+			expected:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := testutils.InitCtx("../tmp")
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			var actions []Action
+			for i := 0; i < tc.actions; i++ {
+				action, err := NewActionAddBook("js", 1517629805)
+				if err != nil {
+					panic(errors.Wrap(err, "Failed to make the action"))
+				}
+				actions = append(actions, action)
+			}
+			if err := WriteActionLog(ctx, actions); err != nil {
+				panic(errors.Wrap(err, "Failed to write the action log"))
+			}
+
+			oldest, err := TakeSnapshot(ctx, infra.SnapshotConfig{})
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to take a snapshot"))
+			}
+			newest, err := TakeSnapshot(ctx, infra.SnapshotConfig{})
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to take a snapshot"))
+			}
+			if tc.uploadedOldest {
+				if err := MarkSnapshotUploaded(ctx, oldest); err != nil {
+					panic(errors.Wrap(err, "Failed to mark the snapshot"))
+				}
+			}
+			if tc.uploadedNewest {
+				if err := MarkSnapshotUploaded(ctx, newest); err != nil {
+					panic(errors.Wrap(err, "Failed to mark the snapshot"))
+				}
+			}
+
+			// Execute
+			uploaded, err := HasUploadedChanges(ctx, oldest)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to check the snapshot"))
+			}
+
+			// Test
+			testutils.AssertEqual(t, uploaded, tc.expected, "uploaded mismatch")
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dnote-io/cli/infra"
+	"github.com/dnote-io/cli/log"
+	"github.com/pkg/errors"
+)
+
+// NoteView is the data given to the output templates for each note
+type NoteView struct {
+	Book     string
+	Index    int
+	UUID     string
+	Title    string
+	Body     string
+	AddedOn  int64
+	EditedOn int64
+	ReadOn   int64
+	Priority string
+}
+
+// NewNoteView returns a view of the note at the index in the book
+func NewNoteView(bookName string, index int, note infra.Note) NoteView {
+	return NoteView{
+		Book:     bookName,
+		Index:    index,
+		UUID:     note.UUID,
+		Title:    note.Title,
+		Body:     note.Content,
+		AddedOn:  note.AddedOn,
+		EditedOn: note.EditedOn,
+		ReadOn:   note.ReadOn,
+		Priority: note.Priority,
+	}
+}
+
+// templateFuncs are the functions available in the output templates
+var templateFuncs = template.FuncMap{
+	// firstline returns the first line of the string
+	"firstline": func(s string) string {
+		return strings.SplitN(s, "\n", 2)[0]
+	},
+	// truncate shortens the string to at most n characters
+	"truncate": func(n int, s string) string {
+		runes := []rune(s)
+		if n >= 0 && len(runes) > n {
+			return string(runes[:n])
+		}
+
+		return s
+	},
+	// date formats the unix timestamp in the local time using the Go layout
+	"date": func(layout string, ts int64) string {
+		if ts == 0 {
+			return ""
+		}
+
+		return time.Unix(ts, 0).Format(layout)
+	},
+}
+
+// NewNoteTemplate parses the text as an output template for notes
+func NewNoteTemplate(text string) (*template.Template, error) {
+	t, err := template.New("note").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid template")
+	}
+
+	return t, nil
+}
+
+// PrintNoteView prints the note view using the template, followed by a
+// newline. Like the porcelain output, it is printed in the quiet mode.
+func PrintNoteView(t *template.Template, view NoteView) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, view); err != nil {
+		return errors.Wrap(err, "Failed to execute the template")
+	}
+
+	fmt.Println(buf.String())
+
+	return nil
+}
+
+// DefaultNoteFields are the fields printed when no fields are given
+var DefaultNoteFields = []string{"book", "index", "content"}
+
+// noteFields returns the values of the fields of the note view
+var noteFields = map[string]func(NoteView) interface{}{
+	"book":      func(v NoteView) interface{} { return v.Book },
+	"index":     func(v NoteView) interface{} { return v.Index },
+	"uuid":      func(v NoteView) interface{} { return v.UUID },
+	"title":     func(v NoteView) interface{} { return v.Title },
+	"content":   func(v NoteView) interface{} { return v.Body },
+	"added_on":  func(v NoteView) interface{} { return v.AddedOn },
+	"edited_on": func(v NoteView) interface{} { return v.EditedOn },
+	"read_on":   func(v NoteView) interface{} { return v.ReadOn },
+	"priority":  func(v NoteView) interface{} { return v.Priority },
+}
+
+// ParseNoteFields parses the comma separated list of the names of the fields
+// to print for each note
+func ParseNoteFields(text string) ([]string, error) {
+	if text == "" {
+		return DefaultNoteFields, nil
+	}
+
+	var ret []string
+	for _, name := range strings.Split(text, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := noteFields[name]; !ok {
+			return nil, errors.Errorf("Unknown field %s. Use book, index, uuid, title, content, added_on, edited_on, read_on, or priority", name)
+		}
+
+		ret = append(ret, name)
+	}
+
+	return ret, nil
+}
+
+// PrintNoteFields prints the fields of the note view separated by tabs. The
+// record ends with a null character if print0 is true, and the fields are
+// printed as they are. Otherwise it ends with a newline, and the tabs and line
+// breaks in the fields are escaped as in the porcelain output.
+func PrintNoteFields(view NoteView, fields []string, print0 bool) {
+	for i, name := range fields {
+		if i > 0 {
+			fmt.Print("\t")
+		}
+
+		val := fmt.Sprint(noteFields[name](view))
+		if !print0 {
+			val = log.EscapeField(val)
+		}
+		fmt.Print(val)
+	}
+
+	if print0 {
+		fmt.Print("\x00")
+	} else {
+		fmt.Print("\n")
+	}
+}
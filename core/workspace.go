@@ -0,0 +1,95 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/dnote-io/cli/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// WorkspaceFilename is the name of the file that sets the default book for
+// the directory containing it and its subdirectories
+const WorkspaceFilename = ".dnote-workspace"
+
+// Workspace holds the defaults for a directory tree
+type Workspace struct {
+	Book string `yaml:"book"`
+}
+
+// findWorkspacePath returns the path to the closest workspace file in the
+// directory or its ancestors. It returns an empty string if none is found.
+func findWorkspacePath(dir string) string {
+	for {
+		path := filepath.Join(dir, WorkspaceFilename)
+		if utils.FileExists(path) {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ReadWorkspace returns the workspace of the current directory. It returns a
+// zero Workspace if the directory is not in a workspace.
+func ReadWorkspace() (Workspace, error) {
+	var ret Workspace
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to get the working directory")
+	}
+
+	path := findWorkspacePath(wd)
+	if path == "" {
+		return ret, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, errors.Wrapf(err, "Failed to read %s", path)
+	}
+
+	if err := yaml.Unmarshal(b, &ret); err != nil {
+		return ret, errors.Wrapf(err, "Failed to unmarshal %s", path)
+	}
+
+	return ret, nil
+}
+
+// WriteWorkspace writes the workspace file in the directory
+func WriteWorkspace(dir string, workspace Workspace) error {
+	b, err := yaml.Marshal(workspace)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal the workspace into YAML")
+	}
+
+	path := filepath.Join(dir, WorkspaceFilename)
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return errors.Wrapf(err, "Failed to write %s", path)
+	}
+
+	return nil
+}
+
+// GetBookName returns the book name given in the args, or the book of the
+// workspace if the args are empty. It returns an empty string if neither is
+// present.
+func GetBookName(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	workspace, err := ReadWorkspace()
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read the workspace")
+	}
+
+	return workspace.Book, nil
+}
@@ -6,12 +6,84 @@ type DnoteCtx struct {
 	HomeDir     string
 	DnoteDir    string
 	APIEndpoint string
+	WebEndpoint string
 }
 
 // Config holds dnote configuration
 type Config struct {
 	Editor string
 	APIKey string
+	// DisableKeychain stores the API key in this file even if the operating
+	// system provides a keychain
+	DisableKeychain bool
+	// Trackers maps the issue trackers, jira and github, to the URL templates
+	// used to link the issue references in notes
+	Trackers map[string]string
+	// Rules choose the book of the notes added without a book name
+	Rules     []Rule
+	Sync      SyncConfig
+	Lint      LintConfig
+	Snapshots SnapshotConfig
+	Drafts    DraftConfig
+	Web       WebConfig
+	// RequestTimeout is the number of seconds to wait for the server to
+	// respond to a request
+	RequestTimeout int
+}
+
+// SnapshotConfig holds the configuration for the snapshots of the local data
+// taken before each sync
+type SnapshotConfig struct {
+	// Count is the number of snapshots to keep
+	Count int
+	// MaxSize is the total size in bytes the snapshots can take. There is no
+	// limit if it is 0.
+	MaxSize int64
+}
+
+// DraftConfig holds the configuration for the drafts saved while a note is
+// written in the editor
+type DraftConfig struct {
+	// Expiry is the number of days after which a draft is discarded
+	Expiry int
+}
+
+// WebConfig holds the URL templates of the pages opened by the web command
+type WebConfig struct {
+	// Book is the URL of the page of a book. {book} is replaced with the name
+	// of the book.
+	Book string
+	// Note is the URL of the page of a note. {uuid} and {book} are replaced
+	// with the UUID of the note and the name of its book.
+	Note string
+}
+
+// LintConfig holds the configuration for checking the notes after they are
+// added or edited
+type LintConfig struct {
+	// Linters are the names of the linters to run. None is run if empty.
+	Linters []string
+	// SpellCommand is the spell checker used by the spell linter, either aspell
+	// or hunspell
+	SpellCommand string
+}
+
+// SyncConfig holds the configuration for syncing with the server
+type SyncConfig struct {
+	// Metered asks for a confirmation before downloading changes larger than
+	// MeteredLimit bytes
+	Metered      bool
+	MeteredLimit int64
+	// Timeout is the number of seconds a sync can take before it is aborted.
+	// There is no limit if it is 0.
+	Timeout int
+}
+
+// Rule puts the notes whose content matches the regular expression pattern
+// into the book
+type Rule struct {
+	Pattern string
+	Book    string
 }
 
 // Dnote holds the whole dnote data
@@ -25,10 +97,21 @@ type Book struct {
 
 // Note represents a single microlesson
 type Note struct {
-	UUID     string `json:"uuid"`
-	Content  string `json:"content"`
+	UUID    string `json:"uuid"`
+	Content string `json:"content"`
+	// Title is the first line of the content
+	Title    string `json:"title"`
 	AddedOn  int64  `json:"added_on"`
 	EditedOn int64  `json:"edited_on"`
+	// CopiedFrom is the UUID of the note this note was duplicated from. It is
+	// kept locally and is not synced.
+	CopiedFrom string `json:"copied_from,omitempty"`
+	// ReadOn is when the note was last marked as read, or 0 if it is unread.
+	// It is kept locally and is not synced.
+	ReadOn int64 `json:"read_on"`
+	// Priority is the triage label of the note: low, med, high, or empty for
+	// none
+	Priority string `json:"priority"`
 }
 
 // Timestamp holds time information
@@ -38,4 +121,6 @@ type Timestamp struct {
 	Bookmark int `yaml:"bookmark"`
 	// timestamp of the most recent action performed by the cli
 	LastAction int64 `yaml:"last_action"`
+	// seconds the server clock was ahead of the local clock during the last sync
+	ClockSkew int64 `yaml:"clock_skew"`
 }
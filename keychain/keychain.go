@@ -0,0 +1,144 @@
+// Package keychain stores credentials in the credential store provided by the
+// operating system
+package keychain
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	service = "dnote"
+	label   = "Dnote API key"
+)
+
+// ErrUnsupported is returned when no credential store is available on the
+// current platform
+var ErrUnsupported = errors.New("No supported keychain is available")
+
+// Store is a credential store holding one secret for each account
+type Store interface {
+	Available() bool
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// System is the credential store of the operating system. It uses the macOS
+// Keychain, and the Secret Service on Linux and the BSDs. Other platforms,
+// including Windows, are not supported.
+type System struct{}
+
+// Available checks if a credential store can be used on the current platform
+func (s System) Available() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux", "openbsd", "freebsd":
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+
+	return false
+}
+
+// Get returns the secret of the account. It returns an empty string if no
+// secret is stored.
+func (s System) Get(account string) (string, error) {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux", "openbsd", "freebsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", ErrUnsupported
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	// Both tools exit with a non-zero status if the item is not found
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", nil
+		}
+
+		return "", errors.Wrap(err, "Failed to run the keychain command")
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Set stores the secret of the account, replacing any existing one. The
+// secret is passed on stdin so that it does not show in the process list.
+func (s System) Set(account, secret string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		// security reads the command from stdin in the interactive mode
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+			quote(service), quote(account), quote(label), quote(secret)))
+	case "linux", "openbsd", "freebsd":
+		cmd = exec.Command("secret-tool", "store", "--label", label, "service", service, "account", account)
+		cmd.Stdin = strings.NewReader(secret)
+	default:
+		return ErrUnsupported
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "Failed to store the secret in the keychain: %s", stderr.String())
+	}
+
+	// security exits with zero in the interactive mode even if the command fails
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return errors.Errorf("Failed to store the secret in the keychain: %s", msg)
+	}
+
+	return nil
+}
+
+// Delete removes the secret of the account, if present
+func (s System) Delete(account string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+	case "linux", "openbsd", "freebsd":
+		cmd = exec.Command("secret-tool", "clear", "service", service, "account", account)
+	default:
+		return ErrUnsupported
+	}
+
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil
+		}
+
+		return errors.Wrap(err, "Failed to run the keychain command")
+	}
+
+	return nil
+}
+
+// quote quotes the argument of a command read by security in the interactive
+// mode
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+
+	return `"` + s + `"`
+}
@@ -2,6 +2,8 @@ package log
 
 import (
 	"fmt"
+	"os"
+	"strings"
 )
 
 var (
@@ -12,44 +14,142 @@ var (
 	ColorGray   = 37
 )
 
+var (
+	// Quiet suppresses the progress and success messages
+	Quiet bool
+	// Porcelain makes commands print their results in a stable format meant
+	// to be parsed by scripts, and suppresses other output
+	Porcelain bool
+)
+
 var indent = "  "
 
+// silent checks if the output other than the results of the porcelain mode
+// is suppressed
+func silent() bool {
+	return Porcelain
+}
+
+// quiet checks if the progress and success messages are suppressed
+func quiet() bool {
+	return Quiet || Porcelain
+}
+
 func Info(msg string) {
+	record(LevelInfo, msg, !quiet())
+
+	if quiet() {
+		return
+	}
 	fmt.Printf("%s\033[%dm%s\033[0m %s\n", indent, ColorBlue, "•", msg)
 }
 
 func Infof(msg string, v ...interface{}) {
+	record(LevelInfo, fmt.Sprintf(msg, v...), !quiet())
+
+	if quiet() {
+		return
+	}
 	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorBlue, "•", fmt.Sprintf(msg, v...))
 }
 
 func Success(msg string) {
+	record(LevelInfo, msg, !quiet())
+
+	if quiet() {
+		return
+	}
 	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorGreen, "✔", msg)
 }
 
 func Successf(msg string, v ...interface{}) {
+	record(LevelInfo, fmt.Sprintf(msg, v...), !quiet())
+
+	if quiet() {
+		return
+	}
 	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorGreen, "✔", fmt.Sprintf(msg, v...))
 }
 
 func Plain(msg string) {
+	if silent() {
+		return
+	}
 	fmt.Printf("%s%s", indent, msg)
 }
 
 func Plainf(msg string, v ...interface{}) {
+	if silent() {
+		return
+	}
 	fmt.Printf("%s%s", indent, fmt.Sprintf(msg, v...))
 }
 
+// Raw prints the message as is, without indentation
+func Raw(msg string) {
+	if silent() {
+		return
+	}
+	fmt.Print(msg)
+}
+
 func Warnf(msg string, v ...interface{}) {
+	record(LevelWarn, fmt.Sprintf(msg, v...), !silent())
+
+	if silent() {
+		return
+	}
 	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorRed, "•", fmt.Sprintf(msg, v...))
 }
 
+// Error prints the error message to stderr
 func Error(msg string) {
-	fmt.Printf("%s\033[%dm%s\033[0m %s\n", indent, ColorRed, "⨯", msg)
+	record(LevelError, msg, true)
+
+	if Porcelain {
+		fmt.Fprintf(os.Stderr, "error: %s\n", msg)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\033[%dm%s\033[0m %s\n", indent, ColorRed, "⨯", msg)
 }
 
 func Printf(msg string, v ...interface{}) {
+	if silent() {
+		return
+	}
+	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorGray, "•", fmt.Sprintf(msg, v...))
+}
+
+// Askf prints a prompt for the user input. Unlike other output, prompts are
+// printed in the porcelain mode.
+func Askf(msg string, v ...interface{}) {
 	fmt.Printf("%s\033[%dm%s\033[0m %s", indent, ColorGray, "•", fmt.Sprintf(msg, v...))
 }
 
+// Fields prints the values separated by tabs on a single line. It is used to
+// print results in the porcelain mode.
+func Fields(v ...interface{}) {
+	for i, val := range v {
+		if i > 0 {
+			fmt.Print("\t")
+		}
+		fmt.Print(EscapeField(fmt.Sprint(val)))
+	}
+	fmt.Print("\n")
+}
+
+var fieldEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// EscapeField escapes the backslashes, tabs and line breaks in the value of a
+// field, so that each record stays on one line with its fields separated by
+// tabs
+func EscapeField(s string) string {
+	return fieldEscaper.Replace(s)
+}
+
 func WithPrefixf(prefixColor int, prefix, msg string, v ...interface{}) {
+	if silent() {
+		return
+	}
 	fmt.Printf("  \033[%dm%s\033[0m %s\n", prefixColor, prefix, fmt.Sprintf(msg, v...))
 }
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the severity of a diagnostic record
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var levelNames = map[Level]string{
+	LevelError: "error",
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+}
+
+// maxFileSize is the size in bytes above which the log file is rotated when
+// it is opened
+var maxFileSize int64 = 1024 * 1024
+
+var (
+	// verbosity is the least severe level that is recorded
+	verbosity    = LevelWarn
+	verbositySet bool
+	file         *os.File
+)
+
+// SetVerbosity sets the least severe level that is recorded by its name
+func SetVerbosity(name string) error {
+	for level, n := range levelNames {
+		if n == name {
+			verbosity = level
+			verbositySet = true
+			return nil
+		}
+	}
+
+	return errors.Errorf("Unknown verbosity %s. Use error, warn, info, or debug", name)
+}
+
+// OpenFile starts writing the records to the file at the path, appending to
+// it. A file larger than maxFileSize is first moved aside to path.1 so that
+// the log does not grow without bound.
+func OpenFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "Failed to create the log directory")
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > maxFileSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return errors.Wrap(err, "Failed to rotate the log file")
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Failed to open the log file")
+	}
+
+	file = f
+	if !verbositySet {
+		verbosity = LevelInfo
+	}
+
+	return nil
+}
+
+// CloseFile stops writing the records to the log file
+func CloseFile() error {
+	if file == nil {
+		return nil
+	}
+
+	err := file.Close()
+	file = nil
+
+	return err
+}
+
+// Debugf records a message meant for diagnosing problems. Unlike the other
+// messages, it is not shown to the user.
+func Debugf(msg string, v ...interface{}) {
+	record(LevelDebug, fmt.Sprintf(msg, v...), false)
+}
+
+// record writes the message to the log file if its level is recorded. If no
+// log file is open and the verbosity was given, a message not already shown
+// to the user is printed to stderr instead.
+func record(level Level, msg string, shown bool) {
+	if level > verbosity {
+		return
+	}
+
+	msg = strings.TrimRight(msg, "\n")
+
+	if file != nil {
+		fmt.Fprintf(file, "%s %-5s %s\n", time.Now().Format(time.RFC3339), strings.ToUpper(levelNames[level]), msg)
+		return
+	}
+
+	if !shown && verbositySet {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", levelNames[level], msg)
+	}
+}
@@ -13,17 +13,38 @@ import (
 
 	// commands
 	"github.com/dnote-io/cli/cmd/add"
+	copycmd "github.com/dnote-io/cli/cmd/copy"
+	"github.com/dnote-io/cli/cmd/diff"
+	"github.com/dnote-io/cli/cmd/doctor"
+	"github.com/dnote-io/cli/cmd/drafts"
+	"github.com/dnote-io/cli/cmd/dup"
 	"github.com/dnote-io/cli/cmd/edit"
+	"github.com/dnote-io/cli/cmd/export"
+	"github.com/dnote-io/cli/cmd/find"
+	"github.com/dnote-io/cli/cmd/help"
+	importcmd "github.com/dnote-io/cli/cmd/import"
+	"github.com/dnote-io/cli/cmd/lint"
 	"github.com/dnote-io/cli/cmd/login"
+	"github.com/dnote-io/cli/cmd/logout"
 	"github.com/dnote-io/cli/cmd/ls"
+	"github.com/dnote-io/cli/cmd/mark"
+	"github.com/dnote-io/cli/cmd/priority"
+	"github.com/dnote-io/cli/cmd/related"
+	"github.com/dnote-io/cli/cmd/remote"
 	"github.com/dnote-io/cli/cmd/remove"
+	"github.com/dnote-io/cli/cmd/replace"
+	"github.com/dnote-io/cli/cmd/rules"
+	"github.com/dnote-io/cli/cmd/snapshots"
 	"github.com/dnote-io/cli/cmd/sync"
 	"github.com/dnote-io/cli/cmd/upgrade"
 	"github.com/dnote-io/cli/cmd/version"
+	"github.com/dnote-io/cli/cmd/web"
+	"github.com/dnote-io/cli/cmd/workspace"
 )
 
-// apiEndpoint is populated during link time
+// apiEndpoint and webEndpoint are populated during link time
 var apiEndpoint string
+var webEndpoint string
 
 func main() {
 	ctx, err := newCtx()
@@ -39,16 +60,39 @@ func main() {
 	root.Register(remove.NewCmd(ctx))
 	root.Register(edit.NewCmd(ctx))
 	root.Register(login.NewCmd(ctx))
+	root.Register(logout.NewCmd(ctx))
 	root.Register(add.NewCmd(ctx))
+	root.Register(drafts.NewCmd(ctx))
 	root.Register(ls.NewCmd(ctx))
+	root.Register(find.NewCmd(ctx))
+	root.Register(copycmd.NewCmd(ctx))
+	root.Register(dup.NewCmd(ctx))
+	root.Register(replace.NewCmd(ctx))
+	root.Register(mark.NewCmd(ctx))
+	root.Register(priority.NewCmd(ctx))
+	root.Register(related.NewCmd(ctx))
 	root.Register(sync.NewCmd(ctx))
+	root.Register(diff.NewCmd(ctx))
+	root.Register(remote.NewCmd(ctx))
+	root.Register(snapshots.NewCmd(ctx))
 	root.Register(version.NewCmd(ctx))
+	root.Register(export.NewCmd(ctx))
+	root.Register(importcmd.NewCmd(ctx))
+	root.Register(web.NewCmd(ctx))
+	root.Register(workspace.NewCmd(ctx))
+	root.Register(rules.NewCmd(ctx))
+	root.Register(lint.NewCmd(ctx))
 	root.Register(upgrade.NewCmd(ctx))
+	root.Register(doctor.NewCmd(ctx))
+	root.SetHelpCommand(help.NewCmd(ctx))
 
-	if err := root.Execute(); err != nil {
+	err = root.Execute()
+	if err != nil {
 		log.Error(err.Error())
-		os.Exit(1)
 	}
+
+	log.CloseFile()
+	os.Exit(core.GetExitCode(err))
 }
 
 func newCtx() (infra.DnoteCtx, error) {
@@ -62,6 +106,7 @@ func newCtx() (infra.DnoteCtx, error) {
 		HomeDir:     homeDir,
 		DnoteDir:    dnoteDir,
 		APIEndpoint: apiEndpoint,
+		WebEndpoint: webEndpoint,
 	}
 
 	return ret, nil
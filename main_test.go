@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -369,3 +372,1351 @@ func TestRemoveBook(t *testing.T) {
 	testutils.AssertEqual(t, book.Name, "linux", "Remaining book name mismatch")
 	testutils.AssertEqual(t, len(book.Notes), 1, "Remaining book should have one note")
 }
+
+func TestLogout(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read config"))
+	}
+	config.APIKey = "test-api-key"
+	// Keep the key in the file so that the keychain of the user is not touched
+	config.DisableKeychain = true
+	if err := core.WriteConfig(ctx, config); err != nil {
+		panic(errors.Wrap(err, "Failed to write config"))
+	}
+
+	// Execute
+	runDnoteCmd(ctx, "logout")
+
+	// Test
+	config, err = core.ReadConfig(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read config"))
+	}
+
+	testutils.AssertEqual(t, config.APIKey, "", "API key was not cleared")
+	testutils.AssertNotEqual(t, config.Editor, "", "Editor should be preserved")
+}
+
+func TestExportImport(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+	archivePath := filepath.Join(ctx.DnoteDir, "js.json")
+
+	// Execute
+	runDnoteCmd(ctx, "export", "js", "-o", archivePath)
+	runDnoteCmd(ctx, "import", archivePath, "-b", "javascript")
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	if len(actions) != 3 {
+		t.Fatalf("action log length mismatch. got %d", len(actions))
+	}
+
+	book := dnote["javascript"]
+	original := dnote["js"]
+
+	testutils.AssertEqual(t, len(dnote), 3, "There should be 3 books")
+	testutils.AssertEqual(t, actions[0].Type, core.ActionAddBook, "action type mismatch")
+	testutils.AssertEqual(t, actions[1].Type, core.ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, actions[2].Type, core.ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, book.Name, "javascript", "Book name mismatch")
+	testutils.AssertEqual(t, len(book.Notes), 2, "Book should have two notes")
+	testutils.AssertEqual(t, book.Notes[0].Content, "Booleans have toString()", "Note content mismatch")
+	testutils.AssertEqual(t, book.Notes[1].Content, "Date object implements mathematical comparisons", "Note content mismatch")
+	testutils.AssertNotEqual(t, book.Notes[0].UUID, original.Notes[0].UUID, "Note should have a new UUID")
+	testutils.AssertEqual(t, len(original.Notes), 2, "Original book should be intact")
+}
+
+func TestImport_LegacyYAML(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote4.json", "dnote")
+	yamlPath, err := filepath.Abs("./testutils/fixtures/legacy.yaml")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get the fixture path"))
+	}
+
+	// Execute
+	runDnoteCmd(ctx, "import", "--from", "legacy-yaml", yamlPath)
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	js := dnote["js"]
+	css := dnote["css"]
+
+	testutils.AssertEqual(t, len(actions), 3, "There should be 3 actions")
+	testutils.AssertEqual(t, len(dnote), 3, "There should be 3 books")
+	testutils.AssertEqual(t, len(js.Notes), 2, "Duplicate notes should be skipped")
+	testutils.AssertEqual(t, js.Notes[0].Content, "Booleans have toString()", "Note content mismatch")
+	testutils.AssertEqual(t, js.Notes[1].Content, "Date object implements mathematical comparisons", "Note content mismatch")
+	testutils.AssertEqual(t, len(css.Notes), 1, "css should have one note")
+	testutils.AssertEqual(t, css.Notes[0].Content, "flexbox is a one-dimensional layout model", "Note content mismatch")
+}
+
+func TestImport_LegacyYAML_Book(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote4.json", "dnote")
+	yamlPath, err := filepath.Abs("./testutils/fixtures/legacy.yaml")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get the fixture path"))
+	}
+
+	// Execute
+	runDnoteCmd(ctx, "import", "--from", "legacy-yaml", yamlPath, "-b", "web")
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	book := dnote["web"]
+
+	testutils.AssertEqual(t, len(book.Notes), 3, "Duplicate notes should be skipped")
+	testutils.AssertEqual(t, book.Notes[0].Content, "flexbox is a one-dimensional layout model", "the notes should be imported in the order of the books")
+	testutils.AssertEqual(t, book.Notes[1].Content, "Booleans have toString()", "Note content mismatch")
+	testutils.AssertEqual(t, book.Notes[2].Content, "Date object implements mathematical comparisons", "Note content mismatch")
+}
+
+func TestImport_ArchiveDuplicates(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+	archivePath := filepath.Join(ctx.DnoteDir, "js.json")
+
+	// Execute
+	runDnoteCmd(ctx, "export", "js", "-o", archivePath)
+	runDnoteCmd(ctx, "import", archivePath)
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	book := dnote["js"]
+
+	testutils.AssertEqual(t, len(book.Notes), 4, "the notes in an archive should all be imported")
+	testutils.AssertEqual(t, book.Notes[2].Content, "Booleans have toString()", "Note content mismatch")
+	testutils.AssertEqual(t, book.Notes[3].Content, "Date object implements mathematical comparisons", "Note content mismatch")
+}
+
+func TestFind_Count(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "O", "--count")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "3\n", "count mismatch")
+}
+
+func TestAdd_Split(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote1.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "add", "js", "--split", "---")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Stdin = bytes.NewBufferString("foo\n---\nbar\nbaz\n---\n\n")
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+	timestamp, err := core.ReadTimestamp(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read the timestamp"))
+	}
+
+	book := dnote["js"]
+
+	testutils.AssertEqual(t, len(actions), 2, "There should be 2 actions")
+	testutils.AssertEqual(t, actions[0].Type, core.ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, actions[1].Type, core.ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, len(book.Notes), 3, "Book should have three notes")
+	testutils.AssertEqual(t, book.Notes[1].Content, "foo", "Note content mismatch")
+	testutils.AssertEqual(t, book.Notes[2].Content, "bar\nbaz", "Note content mismatch")
+	testutils.AssertEqual(t, timestamp.LastAction, actions[1].Timestamp, "last_action mismatch")
+}
+
+func TestAdd_JSONL(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "add", "js", "--jsonl")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Stdin = bytes.NewBufferString("{\"content\": \"foo\"}\n\n{\"content\": \"bar\"}\n")
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	book := dnote["js"]
+
+	testutils.AssertEqual(t, len(actions), 3, "There should be 3 actions")
+	testutils.AssertEqual(t, actions[0].Type, core.ActionAddBook, "action type mismatch")
+	testutils.AssertEqual(t, len(book.Notes), 2, "Book should have two notes")
+	testutils.AssertEqual(t, book.Notes[0].Content, "foo", "Note content mismatch")
+	testutils.AssertEqual(t, book.Notes[1].Content, "bar", "Note content mismatch")
+}
+
+func TestExitCode_LoginRequired(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// Execute
+	cmd, _, err := newDnoteCmd(ctx, "sync")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	err = cmd.Run()
+
+	// Test
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("Expected the command to fail but got %+v", err)
+	}
+
+	status := exitErr.Sys().(interface {
+		ExitStatus() int
+	})
+	testutils.AssertEqual(t, status.ExitStatus(), core.ExitAuthRequired, "exit code mismatch")
+}
+
+func TestLs_Porcelain(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "js", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	expected := "js\t0\tBooleans have toString()\njs\t1\tDate object implements mathematical comparisons\n"
+	testutils.AssertEqual(t, string(out), expected, "output mismatch")
+}
+
+func TestLs_Quiet(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote5.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "js", "--quiet")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	if strings.Contains(string(out), "on book js") {
+		t.Errorf("the progress message should be suppressed. got %s", out)
+	}
+	if !strings.Contains(string(out), "Date object implements mathematical comparisons") {
+		t.Errorf("the notes should be listed. got %s", out)
+	}
+}
+
+func TestLs_PorcelainEscape(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "sh", "-c", "split on tabs\n\n```sh\ncut -d$'\\t' -f1\tfile\n```")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "sh", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	cmd, stderr, err = newDnoteCmd(ctx, "ls", "sh", "--fields", "index,content")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	fieldsOut, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "sh\t0\tsplit on tabs\\n\\n```sh\\ncut -d$'\\\\t' -f1\\tfile\\n```\n", "porcelain output mismatch")
+	testutils.AssertEqual(t, string(fieldsOut), "0\tsplit on tabs\\n\\n```sh\\ncut -d$'\\\\t' -f1\\tfile\\n```\n", "fields output mismatch")
+}
+
+func TestFind_SortLimit(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "o", "--sort", "created", "--limit", "2", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	expected := "linux\t0\twc -l to count words\njs\t1\tDate object implements mathematical comparisons\n"
+	testutils.AssertEqual(t, string(out), expected, "output mismatch")
+}
+
+func TestFind_GroupByMonth(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	dnote := infra.Dnote{
+		"js": infra.Book{Name: "js", Notes: []infra.Note{
+			{UUID: "n1", Content: "closures", AddedOn: 1515199943, EditedOn: 1520000000},
+			{UUID: "n2", Content: "hoisting", AddedOn: 1520000100},
+		}},
+	}
+	if err := core.WriteDnote(ctx, dnote); err != nil {
+		panic(errors.Wrap(err, "Failed to write dnote"))
+	}
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "--since", "2018-03-01", "--group-by", "month", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "2018-03\t2\n", "output mismatch")
+}
+
+func TestAdd_IgnoreCase(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote1.json", "dnote")
+
+	// Execute
+	runDnoteCmd(ctx, "add", "JS", "-c", "foo")
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	book := dnote["js"]
+
+	testutils.AssertEqual(t, len(dnote), 1, "There should be 1 book")
+	testutils.AssertEqual(t, book.Name, "js", "Book name mismatch")
+	testutils.AssertEqual(t, len(book.Notes), 2, "Book should have two notes")
+	testutils.AssertEqual(t, book.Notes[1].Content, "foo", "Note content mismatch")
+}
+
+func TestAdd_Workspace(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	workspaceDir := filepath.Join(ctx.DnoteDir, "project")
+	nestedDir := filepath.Join(workspaceDir, "src")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		panic(errors.Wrap(err, "Failed to make the workspace directory"))
+	}
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "workspace", "init", "project")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Dir = workspaceDir
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	cmd, stderr, err = newDnoteCmd(ctx, "add", "-c", "foo")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Dir = nestedDir
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	book := dnote["project"]
+
+	testutils.AssertEqual(t, len(dnote), 1, "There should be 1 book")
+	testutils.AssertEqual(t, len(book.Notes), 1, "Book should have one note")
+	testutils.AssertEqual(t, book.Notes[0].Content, "foo", "Note content mismatch")
+}
+
+func TestFind_NewWorkspace(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	workspaceDir := filepath.Join(ctx.DnoteDir, "project")
+	if err := os.MkdirAll(workspaceDir, 0755); err != nil {
+		panic(errors.Wrap(err, "Failed to make the workspace directory"))
+	}
+	cmd, stderr, err := newDnoteCmd(ctx, "workspace", "init", "project")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Dir = workspaceDir
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Execute
+	cmd, stderr, err = newDnoteCmd(ctx, "find", "Date", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Dir = workspaceDir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "", "the book of the new workspace should be empty")
+}
+
+func TestLs_Tree(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote5.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "--tree", "-n", "1", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	expected := "js\t1\tDate object implements mathematical comparisons\nlinux\t0\twc -l to count words\n"
+	testutils.AssertEqual(t, string(out), expected, "output mismatch")
+}
+
+func TestLs_Tree_NegativeCount(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote5.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "--tree", "-n", "-1")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	err = cmd.Run()
+
+	// Test
+	if err == nil {
+		t.Fatal("Expected the command to fail")
+	}
+	if !strings.Contains(stderr.String(), "must not be negative") {
+		t.Fatalf("Expected the validation error but got %s", stderr.String())
+	}
+}
+
+func TestLs_Template(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote1.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "js", "--template", "{{.Book}}/{{.Index}}: {{.Body | firstline | truncate 3}}")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "js/0: Boo\n", "output mismatch")
+}
+
+func TestFind_Print0(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "closure\nkeeps the scope")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "closure", "--fields", "book,content", "--print0")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "js\tclosure\nkeeps the scope\x00", "output mismatch")
+}
+
+func TestFind_CodeOnly(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "go", "-c", "channel\n```go\nch := make(chan int)\nch <- 1\n```")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "channel", "--lang", "go", "--code-only")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "ch := make(chan int)\nch <- 1\n", "output mismatch")
+}
+
+func TestAdd_Rules(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnoterc-rules.yaml", "dnoterc")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "add", "--split", "---")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	cmd.Stdin = bytes.NewBufferString("git stash pop\n---\nKubectl logs -f\n---\ngit reflog\n")
+	if err := cmd.Run(); err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	testutils.AssertEqual(t, len(dnote), 2, "There should be 2 books")
+	testutils.AssertEqual(t, len(dnote["git"].Notes), 2, "git should have 2 notes")
+	testutils.AssertEqual(t, len(dnote["k8s"].Notes), 1, "k8s should have 1 note")
+	testutils.AssertEqual(t, dnote["git"].Notes[1].Content, "git reflog", "Note content mismatch")
+	testutils.AssertEqual(t, dnote["k8s"].Notes[0].Content, "Kubectl logs -f", "Note content mismatch")
+}
+
+func TestCopy_Stdout(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	content := "clean up\n```sh\nrm -rf \"$TMPDIR\"/dnote-* && echo '$HOME'\n```\n```\nls\n```"
+	runDnoteCmd(ctx, "add", "sh", "-c", content)
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "copy", "sh", "0", "--stdout")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	cmd, stderr, err = newDnoteCmd(ctx, "copy", "sh", "0", "--stdout", "--block", "2")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out2, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "rm -rf \"$TMPDIR\"/dnote-* && echo '$HOME'", "first block mismatch")
+	testutils.AssertEqual(t, string(out2), "ls", "second block mismatch")
+}
+
+func TestLs_Books(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	expected := "js\t2\t71\t1515199951\nlinux\t1\t20\t1515199961\n"
+	testutils.AssertEqual(t, string(out), expected, "output mismatch")
+}
+
+func TestFind_Fuzzy(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote3.json", "dnote")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "booleen", "--fuzzy", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "js\t0\tBooleans have toString()\n", "output mismatch")
+}
+
+func TestFind_FuzzyHighlight(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "booleans and boolean values")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "boolean", "--fuzzy")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	expected := "\033[32mbooleans\033[0m and \033[32mboolean\033[0m values\n"
+	if !strings.HasSuffix(string(out), expected) {
+		t.Errorf("highlight mismatch. got %q", out)
+	}
+}
+
+func TestLint(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnoterc-lint.yaml", "dnoterc")
+	runDnoteCmd(ctx, "add", "js", "-c", "closures capture variables")
+	runDnoteCmd(ctx, "add", "js", "-c", "FIXME: explain hoisting")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "lint", "js", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "js\t1\ttodo\tcontains FIXME\n", "output mismatch")
+}
+
+func TestReplace(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "var x = 1\nconsole.log(x)")
+	runDnoteCmd(ctx, "add", "js", "-c", "closures")
+	runDnoteCmd(ctx, "add", "go", "-c", "var x int")
+
+	// Execute
+	runDnoteCmd(ctx, "replace", "--book", "js", "--find", "var ", "--replace", "let ", "--yes")
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Content, "let x = 1\nconsole.log(x)", "replaced content mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Title, "let x = 1", "replaced title mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[1].Content, "closures", "unmatched note should not change")
+	testutils.AssertEqual(t, dnote["go"].Notes[0].Content, "var x int", "note in another book should not change")
+	testutils.AssertEqual(t, actions[len(actions)-1].Type, core.ActionEditNote, "action type mismatch")
+}
+
+func TestMark(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "closures")
+	runDnoteCmd(ctx, "add", "js", "-c", "hoisting")
+
+	// Execute
+	runDnoteCmd(ctx, "mark", "read", "js", "0")
+
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "js", "--unread", "--fields", "index,content")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+
+	testutils.AssertNotEqual(t, dnote["js"].Notes[0].ReadOn, int64(0), "note should be marked as read")
+	testutils.AssertEqual(t, dnote["js"].Notes[1].ReadOn, int64(0), "note should be unread")
+	testutils.AssertEqual(t, string(out), "1\thoisting\n", "output mismatch")
+}
+
+func TestDup(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	testutils.WriteFile(ctx, "./testutils/fixtures/dnote1.json", "dnote")
+
+	// Execute
+	runDnoteCmd(ctx, "dup", "js", "0", "ts")
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	source := dnote["js"].Notes[0]
+	copied := dnote["ts"].Notes[0]
+
+	testutils.AssertEqual(t, len(dnote["js"].Notes), 1, "js should have 1 note")
+	testutils.AssertEqual(t, len(dnote["ts"].Notes), 1, "ts should have 1 note")
+	testutils.AssertEqual(t, copied.Content, source.Content, "content mismatch")
+	testutils.AssertEqual(t, copied.CopiedFrom, source.UUID, "copied_from mismatch")
+	testutils.AssertNotEqual(t, copied.UUID, source.UUID, "uuid should be new")
+	testutils.AssertEqual(t, len(actions), 2, "There should be 2 actions")
+	testutils.AssertEqual(t, actions[0].Type, core.ActionAddBook, "action type mismatch")
+	testutils.AssertEqual(t, actions[1].Type, core.ActionAddNote, "action type mismatch")
+}
+
+func TestDiff_Local(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "foo")
+	runDnoteCmd(ctx, "edit", "js", "0", "-c", "bar")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "diff", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	testutils.AssertEqual(t, len(lines), 3, "There should be 3 changes")
+	testutils.AssertEqual(t, strings.Join(strings.Split(lines[0], "\t")[:3], " "), "local add_book js", "change mismatch")
+	testutils.AssertEqual(t, strings.Join(strings.Split(lines[1], "\t")[:3], " "), "local add_note js", "change mismatch")
+	testutils.AssertEqual(t, strings.Join(strings.Split(lines[2], "\t")[:3], " "), "local edit_note js", "change mismatch")
+}
+
+func TestHelpTopics(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "help", "topics", "sync")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, strings.HasPrefix(string(out), "SYNC\n"), true, "topic should be printed without the pager")
+}
+
+func TestPriority(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "closures")
+	runDnoteCmd(ctx, "add", "js", "-c", "hoisting")
+
+	// Execute
+	runDnoteCmd(ctx, "priority", "js", "1", "high")
+
+	cmd, stderr, err := newDnoteCmd(ctx, "find", "--priority", "high", "--fields", "index,priority,content")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	actions, err := core.ReadActionLog(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to read actions"))
+	}
+
+	testutils.AssertEqual(t, dnote["js"].Notes[0].Priority, core.PriorityNone, "other note priority mismatch")
+	testutils.AssertEqual(t, dnote["js"].Notes[1].Priority, core.PriorityHigh, "note priority mismatch")
+	testutils.AssertEqual(t, len(actions), 3, "priority should not be logged")
+	testutils.AssertEqual(t, string(out), "1\thigh\thoisting\n", "output mismatch")
+}
+
+func TestRelated(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	runDnoteCmd(ctx, "add", "js", "-c", "closures capture variables")
+	runDnoteCmd(ctx, "add", "js", "-c", "promises chain")
+	runDnoteCmd(ctx, "add", "go", "-c", "closures in go capture variables by reference")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "related", "js", "0", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	testutils.AssertEqual(t, len(lines), 1, "related notes count mismatch")
+	testutils.AssertEqual(t, strings.HasPrefix(lines[0], "go\t0\t"), true, "related note mismatch")
+}
+
+func TestSync_ShowMigrations(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+	if err := ioutil.WriteFile(filepath.Join(ctx.DnoteDir, core.SchemaFilename), []byte("current_version: 5\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the schema"))
+	}
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "sync", "--show-migrations", "--porcelain")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	testutils.AssertEqual(t, len(lines), 6, "migration count mismatch")
+	testutils.AssertEqual(t, lines[4], "5\textract the titles of notes\tdone", "done migration mismatch")
+	testutils.AssertEqual(t, lines[5], "6\tmerge the books whose names differ only in case\tpending", "pending migration mismatch")
+	testutils.AssertEqual(t, string(testutils.ReadFile(ctx, core.SchemaFilename)), "current_version: 5\n", "the migrations should not be run")
+}
+
+// setEditorCommand sets the editor in the config to the program found in PATH
+// followed by the arguments
+func setEditorCommand(ctx infra.DnoteCtx, program string, args ...string) {
+	path, err := exec.LookPath(program)
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to find %s", program))
+	}
+
+	config, err := core.ReadConfig(ctx)
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to read the config"))
+	}
+
+	config.Editor = strings.Join(append([]string{path}, args...), " ")
+	if err := core.WriteConfig(ctx, config); err != nil {
+		panic(errors.Wrap(err, "Failed to write the config"))
+	}
+}
+
+// setEditor sets the editor in the config to a command copying the file into
+// the file being edited
+func setEditor(ctx infra.DnoteCtx, path string) {
+	setEditorCommand(ctx, "cp", path)
+}
+
+func TestEditor_FencedCodeBlock(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+
+	added := filepath.Join(ctx.DnoteDir, "added.md")
+	if err := ioutil.WriteFile(added, []byte("docker cleanup\r\n\r\n```sh\r\ndocker system prune\r\n```\r\n\r\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the content"))
+	}
+	edited := filepath.Join(ctx.DnoteDir, "edited.md")
+	if err := ioutil.WriteFile(edited, []byte("docker cleanup\n\n```sh\ndocker system prune --volumes\n```\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the content"))
+	}
+
+	// Execute
+	setEditor(ctx, added)
+	runDnoteCmd(ctx, "add", "docker")
+	dnote, err := core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	addedNote := dnote["docker"].Notes[0]
+
+	setEditor(ctx, edited)
+	runDnoteCmd(ctx, "edit", "docker", "0")
+	dnote, err = core.GetDnote(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+	}
+	editedNote := dnote["docker"].Notes[0]
+
+	// Test
+	testutils.AssertEqual(t, addedNote.Content, "docker cleanup\n\n```sh\ndocker system prune\n```", "added content mismatch")
+	testutils.AssertEqual(t, editedNote.Content, "docker cleanup\n\n```sh\ndocker system prune --volumes\n```", "edited content mismatch")
+	testutils.AssertEqual(t, editedNote.Title, "docker cleanup", "title mismatch")
+}
+
+func TestCopy_Editor(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+
+	path := filepath.Join(ctx.DnoteDir, "note.md")
+	if err := ioutil.WriteFile(path, []byte("docker cleanup\n\n```sh\ndocker system prune\ndocker volume prune\n```\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the content"))
+	}
+	setEditor(ctx, path)
+	runDnoteCmd(ctx, "add", "docker")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "copy", "docker", "0", "--stdout")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(out), "docker system prune\ndocker volume prune", "block mismatch")
+}
+
+func TestTitle_Editor(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+
+	path := filepath.Join(ctx.DnoteDir, "note.md")
+	if err := ioutil.WriteFile(path, []byte("\nundo the last commit\n\nkeeps the changes staged\n"), 0644); err != nil {
+		panic(errors.Wrap(err, "Failed to write the content"))
+	}
+	setEditor(ctx, path)
+	runDnoteCmd(ctx, "add", "git")
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "ls", "git", "--fields", "title")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	lsOut, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	cmd, stderr, err = newDnoteCmd(ctx, "find", "staged", "--title", "--fields", "title")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	findOut, err := cmd.Output()
+	if err != nil {
+		panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+	}
+
+	// Test
+	testutils.AssertEqual(t, string(lsOut), "undo the last commit\n", "ls output mismatch")
+	testutils.AssertEqual(t, string(findOut), "", "the second line should not be searched as the title")
+}
+
+func TestAdd_MissingBook(t *testing.T) {
+	// Setup
+	ctx := testutils.InitCtx("./tmp")
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+
+	// init files by running root command
+	runDnoteCmd(ctx)
+
+	// The editor fails if it is opened, because there is no file to copy
+	setEditor(ctx, filepath.Join(ctx.DnoteDir, "missing.md"))
+
+	// Execute
+	cmd, stderr, err := newDnoteCmd(ctx, "add")
+	if err != nil {
+		panic(errors.Wrap(err, "Failed to get command"))
+	}
+	err = cmd.Run()
+
+	// Test
+	if err == nil {
+		t.Fatal("Expected the command to fail")
+	}
+	if !strings.Contains(stderr.String(), "Missing book name") {
+		t.Fatalf("Expected to fail before opening the editor but got %s", stderr.String())
+	}
+}
+
+func TestAdd_RulesDraft(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          string
+		expectedBook   string
+		expectedNote   string
+		unexpectedBook string
+	}{
+		{
+			name:           "resume",
+			input:          "y\n",
+			expectedBook:   "git",
+			expectedNote:   "git stash pop",
+			unexpectedBook: "k8s",
+		},
+		{
+			name:           "discard",
+			input:          "n\n",
+			expectedBook:   "k8s",
+			expectedNote:   "kubectl logs -f",
+			unexpectedBook: "git",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			ctx := testutils.InitCtx("./tmp")
+			testutils.SetupTmp(ctx)
+			defer testutils.ClearTmp(ctx)
+
+			// init files by running root command
+			runDnoteCmd(ctx)
+			testutils.WriteFile(ctx, "./testutils/fixtures/dnoterc-rules.yaml", "dnoterc")
+
+			now := time.Now().Unix()
+			for _, d := range []core.Draft{
+				{ID: "d1", Content: "git stash pop", SavedOn: now},
+				{BookName: "js", Content: "closures", SavedOn: now},
+			} {
+				if err := core.SaveDraft(ctx, infra.DraftConfig{}, d); err != nil {
+					panic(errors.Wrap(err, "Failed to save the draft"))
+				}
+			}
+
+			if tc.input == "y\n" {
+				// Keep the content of the draft as it is
+				setEditorCommand(ctx, "true")
+			} else {
+				path := filepath.Join(ctx.DnoteDir, "note.md")
+				if err := ioutil.WriteFile(path, []byte("kubectl logs -f\n"), 0644); err != nil {
+					panic(errors.Wrap(err, "Failed to write the content"))
+				}
+				setEditor(ctx, path)
+			}
+
+			// Execute
+			cmd, stderr, err := newDnoteCmd(ctx, "add")
+			if err != nil {
+				panic(errors.Wrap(err, "Failed to get command"))
+			}
+			cmd.Stdin = bytes.NewBufferString(tc.input)
+			if err := cmd.Run(); err != nil {
+				panic(errors.Wrapf(err, "Failed to run command %s", stderr.String()))
+			}
+
+			// Test
+			dnote, err := core.GetDnote(ctx)
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to get dnote"))
+			}
+			drafts, err := core.ReadDrafts(ctx, infra.DraftConfig{})
+			if err != nil {
+				t.Fatal(errors.Wrap(err, "Failed to read the drafts"))
+			}
+
+			testutils.AssertEqual(t, len(dnote[tc.expectedBook].Notes), 1, "the note should be added to the book of the rule")
+			testutils.AssertEqual(t, dnote[tc.expectedBook].Notes[0].Content, tc.expectedNote, "Note content mismatch")
+			_, ok := dnote[tc.unexpectedBook]
+			testutils.AssertEqual(t, ok, false, "no note should be added to the other book")
+			testutils.AssertEqual(t, len(drafts), 1, "only the draft of the other book should be left")
+			testutils.AssertEqual(t, drafts[0].BookName, "js", "the draft of the other book should be kept")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package migrate
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -25,6 +26,8 @@ const (
 	migrationV2
 	migrationV3
 	migrationV4
+	migrationV5
+	migrationV6
 )
 
 var migrationSequence = []int{
@@ -32,6 +35,24 @@ var migrationSequence = []int{
 	migrationV2,
 	migrationV3,
 	migrationV4,
+	migrationV5,
+	migrationV6,
+}
+
+var migrationDescriptions = map[int]string{
+	migrationV1: "delete the archived YAML notes",
+	migrationV2: "assign UUIDs to notes",
+	migrationV3: "generate actions for existing notes",
+	migrationV4: "set the editor in the config",
+	migrationV5: "extract the titles of notes",
+	migrationV6: "merge the books whose names differ only in case",
+}
+
+// Info describes a migration and whether it has been run
+type Info struct {
+	ID          int
+	Description string
+	Done        bool
 }
 
 type schema struct {
@@ -85,16 +106,24 @@ func performMigration(ctx infra.DnoteCtx, migrationID int) error {
 		migrationError = migrateToV3(ctx)
 	case migrationV4:
 		migrationError = migrateToV4(ctx)
+	case migrationV5:
+		migrationError = migrateToV5(ctx)
+	case migrationV6:
+		migrationError = migrateToV6(ctx)
 	default:
 		return errors.Errorf("Unrecognized migration id %d", migrationID)
 	}
 
+	if migrationError == nil {
+		migrationError = verifyIntegrity(ctx)
+	}
+
 	if migrationError != nil {
 		if err := restoreBackup(ctx); err != nil {
 			panic(errors.Wrap(err, "Failed to restore backup for a failed migration"))
 		}
 
-		return errors.Wrapf(migrationError, "Failed to perform migration #%d", migrationID)
+		return errors.Wrapf(migrationError, "Migration #%d (%s) failed and your data was restored to the state before it", migrationID, migrationDescriptions[migrationID])
 	}
 
 	if err := clearBackup(ctx); err != nil {
@@ -108,6 +137,49 @@ func performMigration(ctx infra.DnoteCtx, migrationID int) error {
 	return nil
 }
 
+// verifyIntegrity checks that the files in the dnote directory can still be
+// parsed after a migration
+func verifyIntegrity(ctx infra.DnoteCtx) error {
+	jsonFiles := map[string]interface{}{
+		"dnote":   &map[string]interface{}{},
+		"actions": &[]interface{}{},
+	}
+	yamlFiles := []string{"dnoterc", "timestamps", schemaFilename}
+
+	for filename, dest := range jsonFiles {
+		path := fmt.Sprintf("%s/%s", ctx.DnoteDir, filename)
+		if !utils.FileExists(path) {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s", filename)
+		}
+		if err := json.Unmarshal(b, dest); err != nil {
+			return errors.Wrapf(err, "The %s file is corrupt", filename)
+		}
+	}
+
+	for _, filename := range yamlFiles {
+		path := fmt.Sprintf("%s/%s", ctx.DnoteDir, filename)
+		if !utils.FileExists(path) {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to read %s", filename)
+		}
+		var dest map[string]interface{}
+		if err := yaml.Unmarshal(b, &dest); err != nil {
+			return errors.Wrapf(err, "The %s file is corrupt", filename)
+		}
+	}
+
+	return nil
+}
+
 // backupDnoteDir backs up the dnote directory to a temporary backup directory
 func backupDnoteDir(ctx infra.DnoteCtx) error {
 	srcPath := fmt.Sprintf("%s/.dnote", ctx.HomeDir)
@@ -225,6 +297,27 @@ func getUnrunMigrations(ctx infra.DnoteCtx) ([]int, error) {
 	return ret, nil
 }
 
+// Status returns all migrations in the order they are run, and whether each
+// of them has been run
+func Status(ctx infra.DnoteCtx) ([]Info, error) {
+	var ret []Info
+
+	schema, err := readSchema(ctx)
+	if err != nil {
+		return ret, errors.Wrap(err, "Failed to read schema")
+	}
+
+	for idx, mid := range migrationSequence {
+		ret = append(ret, Info{
+			ID:          mid,
+			Description: migrationDescriptions[mid],
+			Done:        idx < schema.CurrentVersion,
+		})
+	}
+
+	return ret, nil
+}
+
 func updateSchemaVersion(ctx infra.DnoteCtx, mID int) error {
 	s, err := readSchema(ctx)
 	if err != nil {
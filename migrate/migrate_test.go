@@ -193,3 +193,138 @@ func TestMigrateToV4(t *testing.T) {
 	testutils.AssertEqual(t, config.APIKey, "Oev6e1082ORasdf9rjkfjkasdfjhgei", "api key mismatch")
 	testutils.AssertEqual(t, config.Editor, "vim", "editor mismatch")
 }
+
+func TestVerifyIntegrity(t *testing.T) {
+	ctx := testutils.InitCtx("../tmp")
+
+	t.Run("valid files", func(t *testing.T) {
+		// set up
+		testutils.SetupTmp(ctx)
+		testutils.WriteFile(ctx, "./fixtures/3-pre-dnote.json", "dnote")
+		testutils.WriteFile(ctx, "./fixtures/4-pre-dnoterc.yaml", "dnoterc")
+		defer testutils.ClearTmp(ctx)
+
+		// execute
+		err := verifyIntegrity(ctx)
+
+		// test
+		if err != nil {
+			t.Fatalf("Expected no error but got %s", err.Error())
+		}
+	})
+
+	t.Run("corrupt dnote", func(t *testing.T) {
+		// set up
+		testutils.SetupTmp(ctx)
+		defer testutils.ClearTmp(ctx)
+
+		dnotePath := filepath.Join(ctx.DnoteDir, "dnote")
+		if err := ioutil.WriteFile(dnotePath, []byte(`{"js": `), 0644); err != nil {
+			panic(errors.Wrap(err, "Failed to write dnote"))
+		}
+
+		// execute
+		err := verifyIntegrity(ctx)
+
+		// test
+		if err == nil {
+			t.Fatal("Expected an error for the corrupt dnote file")
+		}
+	})
+}
+
+func TestStatus(t *testing.T) {
+	ctx := testutils.InitCtx("../tmp")
+
+	// set up
+	testutils.SetupTmp(ctx)
+	defer testutils.ClearTmp(ctx)
+	if err := writeSchema(ctx, schema{CurrentVersion: 2}); err != nil {
+		panic(errors.Wrap(err, "Failed to write schema"))
+	}
+
+	// execute
+	infos, err := Status(ctx)
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to get status").Error())
+	}
+
+	// test
+	testutils.AssertEqual(t, len(infos), len(migrationSequence), "migration count mismatch")
+	testutils.AssertEqual(t, infos[0].Done, true, "migration #1 should be done")
+	testutils.AssertEqual(t, infos[1].Done, true, "migration #2 should be done")
+	testutils.AssertEqual(t, infos[2].Done, false, "migration #3 should be pending")
+	testutils.AssertEqual(t, infos[3].Done, false, "migration #4 should be pending")
+}
+
+func TestMigrateToV5(t *testing.T) {
+	ctx := testutils.InitCtx("../tmp")
+
+	// set up
+	testutils.SetupTmp(ctx)
+	testutils.WriteFile(ctx, "./fixtures/5-pre-dnote.json", "dnote")
+	defer testutils.ClearTmp(ctx)
+
+	// execute
+	if err := migrateToV5(ctx); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to migrate").Error())
+	}
+
+	// test
+	b := testutils.ReadFile(ctx, "dnote")
+	var postDnote migrateToV5PostDnote
+	if err := json.Unmarshal(b, &postDnote); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to unmarshal the result into Dnote").Error())
+	}
+
+	notes := postDnote["git"].Notes
+	testutils.AssertEqual(t, len(notes), 2, "note count mismatch")
+	testutils.AssertEqual(t, notes[0].Title, "undo the last commit", "title mismatch")
+	testutils.AssertEqual(t, notes[0].Content, "\n  undo the last commit\ngit reset --soft HEAD~1", "content was not carried over")
+	testutils.AssertEqual(t, notes[0].EditedOn, int64(1515199950), "edited_on was not carried over")
+	testutils.AssertEqual(t, notes[1].Title, "git log -p shows the patches", "title mismatch")
+}
+
+func TestMigrateToV6(t *testing.T) {
+	ctx := testutils.InitCtx("../tmp")
+
+	// set up
+	testutils.SetupTmp(ctx)
+	testutils.WriteFile(ctx, "./fixtures/6-pre-dnote.json", "dnote")
+	testutils.WriteFile(ctx, "./fixtures/6-pre-actions.json", "actions")
+	defer testutils.ClearTmp(ctx)
+
+	// execute
+	if err := migrateToV6(ctx); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to migrate").Error())
+	}
+
+	// test
+	b := testutils.ReadFile(ctx, "dnote")
+	var postDnote migrateToV6Dnote
+	if err := json.Unmarshal(b, &postDnote); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to unmarshal the result into Dnote").Error())
+	}
+
+	b = testutils.ReadFile(ctx, "actions")
+	var actions []migrateToV6Action
+	if err := json.Unmarshal(b, &actions); err != nil {
+		t.Fatal(errors.Wrap(err, "Failed to unmarshal the actions").Error())
+	}
+
+	testutils.AssertEqual(t, len(postDnote), 2, "book count mismatch")
+	if _, ok := postDnote["Go"]; ok {
+		t.Error("Go should have been merged into go")
+	}
+
+	notes := postDnote["go"].Notes
+	testutils.AssertEqual(t, len(notes), 3, "note count mismatch")
+	testutils.AssertEqual(t, notes[2].Content, "defer runs after the function returns", "merged note content mismatch")
+	testutils.AssertNotEqual(t, notes[2].UUID, "6a1a4d3c-0c5e-4d1b-9f57-3c1e6a8c2b10", "merged note uuid was not regenerated")
+	testutils.AssertEqual(t, notes[2].AddedOn, int64(1515199943), "added_on was not carried over")
+
+	testutils.AssertEqual(t, len(actions), 3, "action count mismatch")
+	testutils.AssertEqual(t, actions[1].Type, migrateToV6ActionAddNote, "action type mismatch")
+	testutils.AssertEqual(t, actions[2].Type, migrateToV6ActionRemoveBook, "action type mismatch")
+	testutils.AssertEqual(t, string(actions[2].Data), `{"book_name":"Go"}`, "action data mismatch")
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/dnote-io/cli/infra"
@@ -190,3 +192,171 @@ func migrateToV4(ctx infra.DnoteCtx) error {
 
 	return nil
 }
+
+// migrateToV5 stores the title of each note, which is its first non-empty line
+func migrateToV5(ctx infra.DnoteCtx) error {
+	notePath := fmt.Sprintf("%s/dnote", ctx.DnoteDir)
+
+	b, err := ioutil.ReadFile(notePath)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the note file")
+	}
+
+	var preDnote migrateToV5PreDnote
+	postDnote := migrateToV5PostDnote{}
+
+	err = json.Unmarshal(b, &preDnote)
+	if err != nil {
+		return errors.Wrap(err, "Failed to unmarshal existing dnote into JSON")
+	}
+
+	for bookName, book := range preDnote {
+		postBook := migrateToV5PostBook{
+			Name:  book.Name,
+			Notes: []migrateToV5PostNote{},
+		}
+
+		for _, note := range book.Notes {
+			var title string
+			for _, line := range strings.Split(note.Content, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					title = line
+					break
+				}
+			}
+
+			postNote := migrateToV5PostNote{
+				UUID:     note.UUID,
+				Content:  note.Content,
+				Title:    title,
+				AddedOn:  note.AddedOn,
+				EditedOn: note.EditedOn,
+			}
+			postBook.Notes = append(postBook.Notes, postNote)
+		}
+
+		postDnote[bookName] = postBook
+	}
+
+	b, err = json.MarshalIndent(postDnote, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal new dnote into JSON")
+	}
+
+	err = ioutil.WriteFile(notePath, b, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write the new dnote into the file")
+	}
+
+	return nil
+}
+
+// migrateToV6 merges the books whose names differ only in case into the one
+// with the most notes, so that book names can be looked up ignoring case. The
+// notes of the merged books are added to the remaining book under new UUIDs
+// and the merged books are removed, so that the server follows on the next
+// sync.
+func migrateToV6(ctx infra.DnoteCtx) error {
+	notePath := fmt.Sprintf("%s/dnote", ctx.DnoteDir)
+	actionsPath := fmt.Sprintf("%s/actions", ctx.DnoteDir)
+
+	b, err := ioutil.ReadFile(notePath)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the note file")
+	}
+
+	var dnote migrateToV6Dnote
+	if err = json.Unmarshal(b, &dnote); err != nil {
+		return errors.Wrap(err, "Failed to unmarshal existing dnote into JSON")
+	}
+
+	b, err = ioutil.ReadFile(actionsPath)
+	if err != nil {
+		return errors.Wrap(err, "Failed to read the actions")
+	}
+
+	var actions []migrateToV6Action
+	if err = json.Unmarshal(b, &actions); err != nil {
+		return errors.Wrap(err, "Failed to unmarshal the actions")
+	}
+
+	groups := map[string][]string{}
+	for bookName := range dnote {
+		key := strings.ToLower(bookName)
+		groups[key] = append(groups[key], bookName)
+	}
+
+	ts := time.Now().Unix()
+	merged := false
+
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+
+		// Keep the book with the most notes, and the first name on ties so
+		// that the result does not depend on the map order
+		sort.Strings(names)
+		canonical := names[0]
+		for _, name := range names[1:] {
+			if len(dnote[name].Notes) > len(dnote[canonical].Notes) {
+				canonical = name
+			}
+		}
+
+		target := dnote[canonical]
+		for _, name := range names {
+			if name == canonical {
+				continue
+			}
+
+			for _, note := range dnote[name].Notes {
+				note.UUID = uuid.NewV4().String()
+				target.Notes = append(target.Notes, note)
+
+				data, err := json.Marshal(map[string]string{
+					"note_uuid": note.UUID,
+					"book_name": canonical,
+					"content":   note.Content,
+				})
+				if err != nil {
+					return errors.Wrap(err, "Failed to marshal the action data")
+				}
+				actions = append(actions, migrateToV6Action{Type: migrateToV6ActionAddNote, Data: data, Timestamp: ts})
+			}
+
+			data, err := json.Marshal(map[string]string{"book_name": name})
+			if err != nil {
+				return errors.Wrap(err, "Failed to marshal the action data")
+			}
+			actions = append(actions, migrateToV6Action{Type: migrateToV6ActionRemoveBook, Data: data, Timestamp: ts})
+
+			delete(dnote, name)
+		}
+
+		dnote[canonical] = target
+		merged = true
+	}
+
+	if !merged {
+		return nil
+	}
+
+	b, err = json.MarshalIndent(dnote, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal new dnote into JSON")
+	}
+	if err = ioutil.WriteFile(notePath, b, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write the new dnote into the file")
+	}
+
+	a, err := json.Marshal(actions)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal actions into JSON")
+	}
+	if err = ioutil.WriteFile(actionsPath, a, 0644); err != nil {
+		return errors.Wrap(err, "Failed to write the actions into a file")
+	}
+
+	return nil
+}
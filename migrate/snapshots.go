@@ -1,5 +1,7 @@
 package migrate
 
+import "encoding/json"
+
 // v2
 type migrateToV2PreNote struct {
 	UID     string
@@ -53,3 +55,54 @@ type migrateToV4PostConfig struct {
 	Editor string
 	APIKey string
 }
+
+// v5
+type migrateToV5PreNote struct {
+	UUID     string `json:"uuid"`
+	Content  string `json:"content"`
+	AddedOn  int64  `json:"added_on"`
+	EditedOn int64  `json:"edited_on"`
+}
+type migrateToV5PostNote struct {
+	UUID     string `json:"uuid"`
+	Content  string `json:"content"`
+	Title    string `json:"title"`
+	AddedOn  int64  `json:"added_on"`
+	EditedOn int64  `json:"edited_on"`
+}
+type migrateToV5PreBook struct {
+	Name  string               `json:"name"`
+	Notes []migrateToV5PreNote `json:"notes"`
+}
+type migrateToV5PostBook struct {
+	Name  string                `json:"name"`
+	Notes []migrateToV5PostNote `json:"notes"`
+}
+type migrateToV5PreDnote map[string]migrateToV5PreBook
+type migrateToV5PostDnote map[string]migrateToV5PostBook
+
+// v6
+var (
+	migrateToV6ActionAddNote    = "add_note"
+	migrateToV6ActionRemoveBook = "remove_book"
+)
+
+type migrateToV6Note struct {
+	UUID       string `json:"uuid"`
+	Content    string `json:"content"`
+	Title      string `json:"title"`
+	AddedOn    int64  `json:"added_on"`
+	EditedOn   int64  `json:"edited_on"`
+	CopiedFrom string `json:"copied_from,omitempty"`
+}
+type migrateToV6Book struct {
+	Name  string            `json:"name"`
+	Notes []migrateToV6Note `json:"notes"`
+}
+type migrateToV6Dnote map[string]migrateToV6Book
+type migrateToV6Action struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp int64           `json:"timestamp"`
+}
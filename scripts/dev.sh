@@ -1,3 +1,3 @@
 #!/bin/bash
 
-rm $(which dnote) $GOPATH/bin/cli && go install -ldflags "-X main.apiEndpoint=http://127.0.0.1:5000" . && ln -s $GOPATH/bin/cli /usr/local/bin/dnote
+rm $(which dnote) $GOPATH/bin/cli && go install -ldflags "-X main.apiEndpoint=http://127.0.0.1:5000 -X main.webEndpoint=http://127.0.0.1:3000" . && ln -s $GOPATH/bin/cli /usr/local/bin/dnote
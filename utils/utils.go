@@ -2,11 +2,15 @@ package utils
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/dnote-io/cli/log"
@@ -34,7 +38,7 @@ func GetInput() (string, error) {
 }
 
 func AskConfirmation(question string) (bool, error) {
-	log.Printf("%s (y/N): ", question)
+	log.Askf("%s (y/N): ", question)
 
 	res, err := GetInput()
 	if err != nil {
@@ -46,6 +50,76 @@ func AskConfirmation(question string) (bool, error) {
 	return confirmed, nil
 }
 
+// OpenBrowser opens the URL in the default web browser
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "Failed to launch the browser")
+	}
+
+	return nil
+}
+
+// getClipboardCmd returns the command that copies its stdin to the clipboard
+func getClipboardCmd() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbcopy"), nil
+	case "windows":
+		return exec.Command("clip"), nil
+	}
+
+	candidates := [][]string{
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c[0]); err == nil {
+			return exec.Command(c[0], c[1:]...), nil
+		}
+	}
+
+	return nil, errors.New("No clipboard command was found. Install xclip, xsel, or wl-copy")
+}
+
+// CopyToClipboard copies the text to the system clipboard as is
+func CopyToClipboard(text string) error {
+	cmd, err := getClipboardCmd()
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "Failed to run %s", cmd.Args[0])
+	}
+
+	return nil
+}
+
+// FormatSize returns the size in bytes in a human readable form
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	}
+
+	return fmt.Sprintf("%dB", size)
+}
+
 // FileExists checks if the file exists at the given path
 func FileExists(filepath string) bool {
 	_, err := os.Stat(filepath)